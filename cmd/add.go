@@ -14,52 +14,86 @@ func AddCommand() *cli.Command {
 		Name:    "add",
 		Aliases: []string{"a"},
 		Usage:   "Add new user",
-		Flags: []cli.Flag{
-			&cli.BoolFlag{
-				Name:  "gpg",
-				Value: false,
-				Usage: "Add GPG key ID",
-			},
-		},
+		Flags:   userFlags(),
 		Action: func(c *cli.Context) error {
-			name, err := prompts.Input("Git user name")
+			// Optional values are only prompted for if the required ones were not provided via flags
+			interactive := c.String("name") == "" || c.String("email") == ""
+
+			gitConfig, err := parseGitConfig(c.StringSlice("git-config"))
 			if err != nil {
 				return err
 			}
 
-			email, err := prompts.InputWithValidation(
-				"Git user email",
-				func(s string) error {
-					return models.ValidateEmail(s, false)
-				},
-			)
+			unlock, err := config.Lock()
 			if err != nil {
 				return err
 			}
+			defer unlock()
 
-			var keyID string
-			if c.Bool("gpg") {
-				keyID, err = prompts.Input("GPG key ID")
+			cfg, err := config.ReadOrNew()
+			if err != nil {
+				return err
+			}
+			policy := cfg.EmailPolicyFor(&models.User{AllowedDomains: allowedDomains(c)})
+
+			name := c.String("name")
+			if name == "" {
+				name, err = prompts.Input("Git user name")
 				if err != nil {
 					return err
 				}
 			}
 
-			alias, err := prompts.Input("User alias, leave empty for no alias")
-			if err != nil {
-				return err
+			email := c.String("email")
+			if email == "" {
+				email, err = prompts.InputWithValidation(
+					"Git user email",
+					func(s string) error {
+						return policy.Validate(s)
+					},
+				)
+				if err != nil {
+					return err
+				}
+			} else {
+				err = policy.Validate(email)
+				if err != nil {
+					return err
+				}
 			}
 
-			user := models.NewUser(name, email, alias, keyID)
-			cfg, err := config.CreateEmptyConfigIfNeeded()
-			if err != nil {
-				return err
+			keyID := c.String("signing-key")
+			if keyID == "" && c.Bool("gpg") {
+				keyID, err = promptSigningKey(c.String("signing-format"), append([]string{email}, c.StringSlice("add-email")...))
+				if err != nil {
+					return err
+				}
 			}
 
+			alias := c.String("alias")
+			if !c.IsSet("alias") && interactive {
+				alias, err = prompts.Input("User alias, leave empty for no alias")
+				if err != nil {
+					return err
+				}
+			}
+
+			user := models.NewUser(name, email, alias, keyID)
+			user.Emails = c.StringSlice("add-email")
+			user.Tags = c.StringSlice("add-tag")
+			user.AllowedDomains = allowedDomains(c)
+			user.SigningFormat = c.String("signing-format")
+			user.SignCommits = c.Bool("sign-commits")
+			user.SignTags = c.Bool("sign-tags")
+			user.AllowedSignersFile = c.String("allowed-signers")
+			user.SSHKey = c.String("ssh-key")
+			user.GitConfig = gitConfig
+
 			err = cfg.AddUser(user)
 			if err != nil {
 				return err
 			}
+			warnSigningKey(user)
 
 			return config.Write(cfg)
 		},
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// emailStats describes how often an email address occurs in the audited commits
+type emailStats struct {
+	Email     string
+	Names     []string
+	Authored  int
+	Committed int
+}
+
+// AuditCommand returns the definition for the 'gitsu audit' command
+func AuditCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "audit",
+		Usage:     "Find commits authored or committed with an email not belonging to the expected user",
+		ArgsUsage: "[rev-range]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "alias",
+				Usage: "Alias of the expected user, defaults to the user of the matching rule or binding",
+			},
+			&cli.StringFlag{
+				Name:  "remote",
+				Value: "origin",
+				Usage: "Name of the remote to match against the rules",
+			},
+			&cli.BoolFlag{
+				Name:  "mailmap",
+				Value: false,
+				Usage: "Add entries mapping the wrong emails to the expected user to the repository's .mailmap",
+			},
+			&cli.StringFlag{
+				Name:  "filter-repo",
+				Usage: "Write a mailmap file for 'git filter-repo --mailmap <file>' rewriting the wrong emails",
+			},
+			&cli.StringSliceFlag{
+				Name:  "email",
+				Usage: "Wrong email to map in addition to the ones of other stored profiles, can be repeated",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			err = git.IsInsideWorktree(models.Local)
+			if err != nil {
+				return err
+			}
+
+			dir, err := git.TopLevel()
+			if err != nil {
+				return err
+			}
+
+			user, err := auditedUser(c, cfg)
+			if err != nil {
+				return err
+			}
+
+			revRange := c.Args().First()
+			if revRange == "" {
+				revRange = "HEAD"
+			}
+
+			commits, err := git.Log(revRange)
+			if err != nil {
+				return err
+			}
+
+			stats := collectEmailStats(commits)
+			var wrong []emailStats
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "EMAIL\tAUTHORED\tCOMMITTED\tNAMES\t")
+			for _, s := range stats {
+				status := ""
+				if !user.HasEmail(s.Email) {
+					status = "not " + user.Format(0)
+					wrong = append(wrong, s)
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", s.Email, s.Authored, s.Committed, strings.Join(s.Names, ", "), status)
+			}
+			err = w.Flush()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("\n%d of %d email(s) in %d commit(s) do not belong to %s\n", len(wrong), len(stats), len(commits), user.Format(0))
+			if len(wrong) == 0 {
+				return nil
+			}
+
+			if !c.Bool("mailmap") && c.String("filter-repo") == "" {
+				return nil
+			}
+
+			// Only the emails of the own profiles are mapped, other emails may belong to coworkers or bots
+			mapped := mappedEmails(cfg, user, wrong, c.StringSlice("email"))
+			if len(mapped) == 0 {
+				fmt.Println("None of the emails belongs to another stored profile, select the ones to map with --email")
+				return nil
+			}
+
+			if c.Bool("mailmap") {
+				path := filepath.Join(dir, ".mailmap")
+				err = appendMailmap(path, user, mapped)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Updated %s\n", path)
+			}
+
+			if path := c.String("filter-repo"); path != "" {
+				file, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+
+				err = writeMailmap(file, user, mapped, nil)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Wrote %s, rewrite the history with 'git filter-repo --mailmap %s'\n", path, path)
+			}
+
+			return nil
+		},
+	}
+}
+
+// auditedUser returns the user selected via the alias flag or the user a rule or binding expects for the repository
+func auditedUser(c *cli.Context, cfg *config.Config) (*models.User, error) {
+	if alias := c.String("alias"); alias != "" {
+		user, err := cfg.SelectUserByAlias(alias)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, alias)
+		}
+		return user, nil
+	}
+
+	user, _, err := expectedUser(cfg, c.String("remote"))
+	if err != nil {
+		return nil, fmt.Errorf("%w, select the expected user with --alias", err)
+	}
+	return user, nil
+}
+
+// mappedEmails returns the wrong emails that belong to other stored profiles or are given explicitly
+func mappedEmails(cfg *config.Config, user *models.User, wrong []emailStats, emails []string) []emailStats {
+	var mapped []emailStats
+	for _, s := range wrong {
+		explicit := false
+		for _, email := range emails {
+			if strings.EqualFold(email, s.Email) {
+				explicit = true
+				break
+			}
+		}
+
+		if other, err := cfg.FindUserByEmail(s.Email); explicit || (err == nil && other.ID != user.ID) {
+			mapped = append(mapped, s)
+		}
+	}
+	return mapped
+}
+
+// collectEmailStats groups the commits by author and committer email, ordered by email
+func collectEmailStats(commits []git.Commit) []emailStats {
+	byEmail := make(map[string]*emailStats)
+	get := func(email, name string) *emailStats {
+		s, ok := byEmail[email]
+		if !ok {
+			s = &emailStats{Email: email}
+			byEmail[email] = s
+		}
+		for _, n := range s.Names {
+			if n == name {
+				return s
+			}
+		}
+		s.Names = append(s.Names, name)
+		return s
+	}
+
+	for _, commit := range commits {
+		get(commit.AuthorEmail, commit.AuthorName).Authored++
+		get(commit.CommitterEmail, commit.CommitterName).Committed++
+	}
+
+	stats := make([]emailStats, 0, len(byEmail))
+	for _, s := range byEmail {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Email < stats[j].Email
+	})
+	return stats
+}
+
+// appendMailmap adds mailmap entries for the wrong emails to the file at path, skipping entries it already contains
+func appendMailmap(path string, user *models.User, wrong []emailStats) error {
+	existing := make(map[string]bool)
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if len(b) > 0 && !strings.HasSuffix(string(b), "\n") {
+		fmt.Fprintln(file)
+	}
+	return writeMailmap(file, user, wrong, existing)
+}
+
+// writeMailmap writes a mailmap entry mapping each wrong email to the user's name and email, entries contained in
+// skip are left out
+func writeMailmap(out io.Writer, user *models.User, wrong []emailStats, skip map[string]bool) error {
+	for _, s := range wrong {
+		entry := fmt.Sprintf("%s <%s> <%s>", user.Name, user.Email, s.Email)
+		if skip[entry] {
+			continue
+		}
+
+		_, err := fmt.Fprintln(out, entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// AutoCommand returns the definition for the 'gitsu auto' command
+func AutoCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "auto",
+		Usage: "Select user by matching the remote URL against the rules",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "remote",
+				Value: "origin",
+				Usage: "Name of the remote to match",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			err = git.IsInsideWorktree(models.Local)
+			if err != nil {
+				return err
+			}
+
+			remoteURL, err := git.RemoteURL(c.String("remote"))
+			if err != nil {
+				return err
+			}
+
+			rule, err := cfg.MatchRule(remoteURL)
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, remoteURL)
+			}
+
+			user, err := cfg.SelectUserByAlias(rule.Alias)
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, rule.Alias)
+			}
+
+			err = git.SetConfig(user, models.Local)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Setting profile %s (rule %s)\n", user.Format(0), rule.Pattern)
+			return nil
+		},
+	}
+}
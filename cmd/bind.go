@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// BindCommand returns the definition for the 'gitsu bind' command
+func BindCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "bind",
+		Usage:     "Use a user for all repositories below a directory, list bindings without arguments",
+		ArgsUsage: "[<alias> <dir>]",
+		Action: func(c *cli.Context) error {
+			unlock, err := config.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			if c.NArg() == 0 {
+				list := cfg.BindingList()
+				if len(list) == 0 {
+					fmt.Println("No bindings")
+					return nil
+				}
+
+				for _, binding := range list {
+					fmt.Println(binding)
+				}
+				return nil
+			}
+
+			if c.NArg() != 2 {
+				return fmt.Errorf("expected an alias and a directory, got %d argument(s)", c.NArg())
+			}
+
+			dir, err := bindingDir(c.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			binding := models.NewBinding(dir, c.Args().Get(0))
+			err = cfg.AddBinding(binding)
+			if err != nil {
+				return err
+			}
+
+			user, err := cfg.SelectUserByAlias(binding.Alias)
+			if err != nil {
+				return err
+			}
+
+			path, err := config.IncludePath(user.Alias)
+			if err != nil {
+				return err
+			}
+
+			// Persist the binding first so that the git config never includes a file gitsu doesn't know about
+			err = config.Write(cfg)
+			if err != nil {
+				return err
+			}
+
+			err = addBindingInclude(user, binding, path)
+			if err != nil {
+				_, _ = cfg.DeleteBinding(binding.Dir)
+				if rollbackErr := config.Write(cfg); rollbackErr != nil {
+					return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+				}
+				return err
+			}
+
+			fmt.Printf("Binding %s to profile %s\n", binding.Dir, user.Format(0))
+			return nil
+		},
+	}
+}
+
+// UnbindCommand returns the definition for the 'gitsu unbind' command
+func UnbindCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "unbind",
+		Usage:     "Remove the binding of a directory",
+		ArgsUsage: "<dir>",
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return fmt.Errorf("expected a directory, got %d argument(s)", c.NArg())
+			}
+
+			unlock, err := config.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			dir, err := bindingDir(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			binding, err := cfg.DeleteBinding(models.NewBinding(dir, "").Dir)
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, dir)
+			}
+
+			err = removeBindingInclude(cfg, binding)
+			if err != nil {
+				return err
+			}
+
+			return config.Write(cfg)
+		},
+	}
+}
+
+// addBindingInclude writes the include file of the user and adds the includeIf option of the binding. A newly written
+// include file is removed again if adding the option fails
+func addBindingInclude(user *models.User, binding *models.Binding, path string) error {
+	_, statErr := os.Stat(path)
+
+	err := git.WriteIncludeFile(user, path)
+	if err != nil {
+		return err
+	}
+
+	err = git.AddInclude(binding.Condition(), path)
+	if err != nil {
+		if os.IsNotExist(statErr) {
+			_ = os.Remove(path)
+		}
+		return err
+	}
+	return nil
+}
+
+// removeBindingInclude removes the includeIf option of a deleted binding and the include file of its user once no
+// other binding uses it
+func removeBindingInclude(cfg *config.Config, binding *models.Binding) error {
+	path, err := config.IncludePath(binding.Alias)
+	if err != nil {
+		return err
+	}
+
+	err = git.RemoveInclude(binding.Condition(), path)
+	if err != nil {
+		return err
+	}
+
+	// The include file is shared by all bindings of the user
+	if len(cfg.BindingsByAlias(binding.Alias)) == 0 {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncBindings updates the include file and includeIf options of a modified user that is bound to directories
+func syncBindings(cfg *config.Config, oldAlias string, user *models.User) error {
+	bindings := cfg.BindingsByAlias(user.Alias)
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	path, err := config.IncludePath(user.Alias)
+	if err != nil {
+		return err
+	}
+
+	err = git.WriteIncludeFile(user, path)
+	if err != nil {
+		return err
+	}
+
+	if oldAlias == user.Alias {
+		return nil
+	}
+
+	oldPath, err := config.IncludePath(oldAlias)
+	if err != nil {
+		return err
+	}
+
+	for _, binding := range bindings {
+		err = git.RemoveInclude(binding.Condition(), oldPath)
+		if err != nil {
+			return err
+		}
+
+		err = git.AddInclude(binding.Condition(), path)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = os.Remove(oldPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// bindingDir returns the directory in the form used by includeIf gitdir conditions
+func bindingDir(dir string) (string, error) {
+	if strings.HasPrefix(dir, "~/") {
+		return dir, nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(abs), nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ConfigCommand returns the definition for the 'gitsu config' command
+func ConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Show information about the gitsu config file",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "path",
+				Usage: "Print the path of the config file",
+				Description: "The config file is selected by the first of:\n" +
+					"   1. the --config flag, e.g. 'gitsu --config ~/gitsu.yaml config path'\n" +
+					"   2. the GITSU_CONFIG environment variable\n" +
+					"   3. gitsu-go/config.json in $XDG_CONFIG_HOME if it is set to an absolute path\n" +
+					"   4. gitsu-go/config.json in the user config directory of the OS\n" +
+					"   Generated include files, hooks, the lock file and backups are kept next to the config file.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dir",
+						Usage: "Print the config directory instead",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					path, err := config.Path()
+					if c.Bool("dir") {
+						path, err = config.Dir()
+					}
+					if err != nil {
+						return err
+					}
+
+					fmt.Println(path)
+					return nil
+				},
+			},
+		},
+	}
+}
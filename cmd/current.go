@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+
+	"github.com/urfave/cli/v2"
+)
+
+// identityOptions are the git config options that make up the identity of a user
+var identityOptions = []string{"user.name", "user.email", "user.signingkey", "gpg.format", "core.sshCommand"}
+
+// CurrentCommand returns the definition for the 'gitsu current' command
+func CurrentCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "current",
+		Aliases: []string{"whoami"},
+		Usage:   "Show the effective git user and the matching profile",
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			values := make(map[string]string)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, option := range identityOptions {
+				value, origin, err := lookupConfig(option)
+				if err != nil {
+					return err
+				}
+
+				values[option] = value
+				if value == "" {
+					fmt.Fprintf(w, "%s\t<not set>\t\n", option)
+				} else {
+					fmt.Fprintf(w, "%s\t%s\t(%s)\n", option, value, origin)
+				}
+			}
+
+			err = w.Flush()
+			if err != nil {
+				return err
+			}
+
+			user, err := cfg.FindUser(values["user.name"], values["user.email"])
+			if err == nil {
+				fmt.Printf("Profile %s\n", user.Format(0))
+			} else if user, err = cfg.FindUserByEmail(values["user.email"]); err == nil {
+				fmt.Printf("Email matches profile %s, but the name differs\n", user.Format(0))
+			} else {
+				fmt.Println("Matches no stored profile")
+			}
+
+			if user != nil && user.SigningKey != values["user.signingkey"] {
+				fmt.Println("Signing key differs from the profile")
+			}
+
+			return nil
+		},
+	}
+}
+
+// lookupConfig returns the value of a git config option as git uses it and where it came from, i.e. the scope and
+// the file setting it. Values from a file included for a bound directory are reported with the scope of the including
+// file and the path of the included one
+func lookupConfig(option string) (string, string, error) {
+	origin, err := git.GetConfigOrigin(option)
+	if err != nil || origin == nil {
+		return "", "", err
+	}
+
+	if origin.Scope == "" {
+		return origin.Value, origin.Origin, nil
+	}
+	return origin.Value, fmt.Sprintf("%s, %s", origin.Scope, origin.Origin), nil
+}
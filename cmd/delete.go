@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/matsuyoshi30/gitsu/cmd/prompts"
 	"github.com/matsuyoshi30/gitsu/internal/config"
 
 	"github.com/urfave/cli/v2"
@@ -11,10 +10,17 @@ import (
 
 func DeleteCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "delete",
-		Aliases: []string{"d"},
-		Usage:   "Delete existing user",
+		Name:      "delete",
+		Aliases:   []string{"d"},
+		Usage:     "Delete existing user",
+		ArgsUsage: "[ID, alias or position]",
 		Action: func(c *cli.Context) error {
+			unlock, err := config.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			cfg, err := config.Read()
 			if err != nil {
 				return err
@@ -26,12 +32,12 @@ func DeleteCommand() *cli.Command {
 				return nil
 			}
 
-			index, _, err := prompts.SelectionCustom("Select git user", list)
+			id, err := selectUserID(c, cfg, "Select git user")
 			if err != nil {
 				return err
 			}
 
-			err = cfg.DeleteUser(index)
+			err = cfg.DeleteUser(id)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/gpg"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+	"github.com/matsuyoshi30/gitsu/internal/utils"
+
+	"github.com/urfave/cli/v2"
+)
+
+// checkStatus is the outcome of a doctor check
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+// checkMarks are the checklist marks of the check statuses
+var checkMarks = map[checkStatus]string{
+	checkOK:   "✔",
+	checkWarn: "!",
+	checkFail: "✘",
+}
+
+// minGitVersion is the git version introducing includeIf, which directory bindings rely on
+var minGitVersion = git.Version{Major: 2, Minor: 13}
+
+// sshSigningGitVersion is the git version introducing SSH signing via gpg.format ssh
+var sshSigningGitVersion = git.Version{Major: 2, Minor: 34}
+
+// checklist collects the results of the doctor checks and prints them as they are added
+type checklist struct {
+	failures int
+	warnings int
+}
+
+// add prints the result of a check and its fix hint, if the check did not pass
+func (l *checklist) add(status checkStatus, message, hint string) {
+	fmt.Printf("%s %s\n", checkMarks[status], message)
+	if status != checkOK && hint != "" {
+		fmt.Printf("  → %s\n", hint)
+	}
+
+	switch status {
+	case checkWarn:
+		l.warnings++
+	case checkFail:
+		l.failures++
+	}
+}
+
+// DoctorCommand returns the definition for the 'gitsu doctor' command
+func DoctorCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "doctor",
+		Usage: "Check the config, the user profiles, git and the current repository for identity problems",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Exit with an error on warnings as well",
+			},
+			&cli.StringFlag{
+				Name:  "remote",
+				Value: "origin",
+				Usage: "Name of the remote to match against the rules",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			l := &checklist{}
+
+			cfg := checkConfig(l)
+			if cfg != nil {
+				checkAliases(l, cfg)
+				checkEmails(l, cfg)
+				checkKeys(l, cfg)
+			}
+			checkGit(l, cfg)
+			if cfg != nil {
+				checkRepository(l, cfg, c.String("remote"))
+			}
+
+			fmt.Printf("\n%d failure(s), %d warning(s)\n", l.failures, l.warnings)
+			if l.failures > 0 || (c.Bool("strict") && l.warnings > 0) {
+				return fmt.Errorf("doctor found %d failure(s) and %d warning(s)", l.failures, l.warnings)
+			}
+			return nil
+		},
+	}
+}
+
+// checkConfig checks that the config file parses and is at the current schema version. Returns nil if the config can
+// not be read
+func checkConfig(l *checklist) *config.Config {
+	path, err := config.Path()
+	if err != nil {
+		l.add(checkFail, fmt.Sprintf("Config file can not be located: %s", err), "Set the config file with --config or GITSU_CONFIG")
+		return nil
+	}
+
+	cfg, err := config.Read()
+	if errors.Is(err, config.ErrConfigFileDoesNotExist) {
+		l.add(checkFail, fmt.Sprintf("Config file %s does not exist", path), "Add a user with 'gitsu add' or import existing identities with 'gitsu import'")
+		return nil
+	}
+	if err != nil {
+		l.add(checkFail, fmt.Sprintf("Config file %s can not be read: %s", path, err), "Fix the file or restore the latest backup "+config.BackupPath(path, 1))
+		return nil
+	}
+
+	if cfg.StoredVersion() < config.CurrentVersion() {
+		l.add(
+			checkWarn,
+			fmt.Sprintf("Config file %s has schema version %d, the current one is %d", path, cfg.StoredVersion(), config.CurrentVersion()),
+			"The file is migrated the next time gitsu changes it, e.g. with 'gitsu modify'",
+		)
+		return cfg
+	}
+
+	l.add(checkOK, fmt.Sprintf("Config file %s parses (schema version %d)", path, cfg.StoredVersion()), "")
+	return cfg
+}
+
+// checkAliases checks that aliases are unique and that rules and bindings point at existing users
+func checkAliases(l *checklist, cfg *config.Config) {
+	failures := l.failures
+	seen := make(map[string]bool)
+	for _, user := range cfg.Users {
+		if user.Alias == "" {
+			continue
+		}
+		if seen[user.Alias] {
+			l.add(checkFail, fmt.Sprintf("Alias [%s] is used by several users", user.Alias), "Rename one of them with 'gitsu modify --alias <alias> <ID>'")
+		}
+		seen[user.Alias] = true
+	}
+
+	for _, rule := range cfg.Rules {
+		if !seen[rule.Alias] {
+			l.add(checkFail, fmt.Sprintf("Rule %s points at the missing user [%s]", rule.Pattern, rule.Alias), fmt.Sprintf("Delete it with 'gitsu rule delete %s'", rule.Pattern))
+		}
+	}
+	for _, binding := range cfg.Bindings {
+		if !seen[binding.Alias] {
+			l.add(checkFail, fmt.Sprintf("Binding of %s points at the missing user [%s]", binding.Dir, binding.Alias), fmt.Sprintf("Remove it with 'gitsu unbind %s'", binding.Dir))
+		}
+	}
+
+	if l.failures == failures {
+		l.add(checkOK, "Aliases are unique and all rules and bindings point at existing users", "")
+	}
+}
+
+// checkEmails checks the emails of all users against the email policies
+func checkEmails(l *checklist, cfg *config.Config) {
+	failures := l.failures
+	for i := range cfg.Users {
+		user := &cfg.Users[i]
+		err := user.ValidateEmails(cfg.EmailPolicyFor(user))
+		if err != nil {
+			l.add(checkFail, fmt.Sprintf("%s has an invalid email: %s", user.Format(0), err), fmt.Sprintf("Change it with 'gitsu modify --email <email> %s'", userRef(user)))
+		}
+	}
+
+	if l.failures == failures {
+		l.add(checkOK, fmt.Sprintf("All %d user(s) have valid emails", len(cfg.Users)), "")
+	}
+}
+
+// checkKeys checks that the signing keys, SSH keys and allowed signers files referenced by the users exist and that
+// GPG keys are usable for the users' emails
+func checkKeys(l *checklist, cfg *config.Config) {
+	failures, warnings := l.failures, l.warnings
+
+	var keys []gpg.Key
+	var keysErr error
+	keysLoaded := false
+	for i := range cfg.Users {
+		user := &cfg.Users[i]
+		if user.SigningKey != "" && isOpenPGP(user.SigningFormat) {
+			if !keysLoaded {
+				keys, keysErr = gpg.ListSecretKeys()
+				keysLoaded = true
+			}
+			checkGPGKey(l, user, keys, keysErr)
+		}
+
+		if user.SigningKey != "" && user.SigningFormat == "ssh" && !strings.HasPrefix(user.SigningKey, "key::") {
+			checkFile(l, checkFail, user, "signing key", user.SigningKey, "--signing-key")
+		}
+		if user.SSHKey != "" {
+			checkFile(l, checkFail, user, "SSH key", user.SSHKey, "--ssh-key")
+		}
+		if user.AllowedSignersFile != "" {
+			checkFile(l, checkWarn, user, "allowed signers file", user.AllowedSignersFile, "--allowed-signers")
+		}
+	}
+
+	if l.failures == failures && l.warnings == warnings {
+		l.add(checkOK, "All referenced signing keys, SSH keys and allowed signers files exist", "")
+	}
+}
+
+// checkGPGKey checks that the user's OpenPGP signing key is in the keyring, usable and has a user ID with the user's
+// email
+func checkGPGKey(l *checklist, user *models.User, keys []gpg.Key, keysErr error) {
+	hint := fmt.Sprintf("Select another key with 'gitsu modify --signing-key <key ID> %s'", userRef(user))
+	if keysErr != nil {
+		l.add(checkFail, fmt.Sprintf("%s: the signing key can not be checked: %s", user.Format(0), keysErr), "Install GnuPG or remove the key with 'gitsu modify --clear-signing-key "+userRef(user)+"'")
+		return
+	}
+
+	key, err := gpg.FindKey(keys, user.SigningKey)
+	if err != nil {
+		l.add(
+			checkFail,
+			fmt.Sprintf("%s: %s", user.Format(0), err),
+			fmt.Sprintf("Import the secret key with 'gpg --import' or select another key with 'gitsu modify --signing-key <key ID> %s'", userRef(user)),
+		)
+		return
+	}
+
+	for _, problem := range key.Problems(user.AllEmails()) {
+		status := checkWarn
+		if !key.Usable() {
+			status = checkFail
+		}
+		l.add(status, fmt.Sprintf("%s: %s", user.Format(0), problem), hint)
+	}
+}
+
+// checkFile checks that a file referenced by the user exists
+func checkFile(l *checklist, status checkStatus, user *models.User, description, path, flag string) {
+	expanded, err := utils.ExpandHome(path)
+	if err != nil {
+		l.add(status, fmt.Sprintf("%s: %s %s can not be checked: %s", user.Format(0), description, path, err), "")
+		return
+	}
+
+	if !utils.FileExists(expanded) {
+		l.add(status, fmt.Sprintf("%s: %s %s does not exist", user.Format(0), description, path), fmt.Sprintf("Fix the path with 'gitsu modify %s <path> %s'", flag, userRef(user)))
+	}
+}
+
+// checkGit checks that git is on PATH and recent enough for the features the users need
+func checkGit(l *checklist, cfg *config.Config) {
+	version, err := git.GetVersion()
+	if err != nil {
+		l.add(checkFail, err.Error(), "Install git and make sure it is on PATH")
+		return
+	}
+
+	if !version.AtLeast(minGitVersion.Major, minGitVersion.Minor) {
+		l.add(checkFail, fmt.Sprintf("git %s is older than %d.%d", version, minGitVersion.Major, minGitVersion.Minor), "Upgrade git")
+		return
+	}
+
+	if cfg != nil && !version.AtLeast(sshSigningGitVersion.Major, sshSigningGitVersion.Minor) {
+		for i := range cfg.Users {
+			if cfg.Users[i].SigningFormat == "ssh" {
+				l.add(
+					checkFail,
+					fmt.Sprintf("git %s does not support SSH signing used by %s, it requires %d.%d", version, cfg.Users[i].Format(0), sshSigningGitVersion.Major, sshSigningGitVersion.Minor),
+					"Upgrade git",
+				)
+				return
+			}
+		}
+	}
+
+	l.add(checkOK, fmt.Sprintf("git %s is on PATH", version), "")
+}
+
+// checkRepository checks that the effective identity of the current repository matches a stored profile and the
+// profile rules and bindings expect. Outside of repositories the check is skipped
+func checkRepository(l *checklist, cfg *config.Config, remote string) {
+	if git.IsInsideWorktree(models.Local) != nil {
+		l.add(checkOK, "Not inside a git repository, skipped the repository identity check", "")
+		return
+	}
+
+	name, err := git.GetEffectiveConfig("user.name")
+	if err != nil {
+		l.add(checkFail, err.Error(), "")
+		return
+	}
+	email, err := git.GetEffectiveConfig("user.email")
+	if err != nil {
+		l.add(checkFail, err.Error(), "")
+		return
+	}
+
+	user, err := cfg.FindUser(name, email)
+	if err != nil {
+		l.add(checkFail, fmt.Sprintf("The repository identity %s <%s> matches no profile", name, email), "Select a profile with 'gitsu select' or add this identity with 'gitsu add'")
+		return
+	}
+
+	expected, reason, err := expectedUser(cfg, remote)
+	if err != nil && !errors.Is(err, config.ErrNoExpectedUser) {
+		l.add(checkFail, err.Error(), "")
+		return
+	}
+	if err == nil && expected.ID != user.ID {
+		l.add(
+			checkFail,
+			fmt.Sprintf("The repository uses %s, but %s expects %s", user.Format(0), reason, expected.Format(0)),
+			fmt.Sprintf("Run 'gitsu select %s'", userRef(expected)),
+		)
+		return
+	}
+
+	l.add(checkOK, fmt.Sprintf("The repository identity matches %s", user.Format(0)), "")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ExportCommand returns the definition for the 'gitsu export' command
+func ExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Export user profiles as a portable bundle",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "alias",
+				Usage: "Only export the user with this alias, can be repeated",
+			},
+			tagFilterFlag(),
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Bundle format: json, yaml or toml, defaults to the extension of the output file or json",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "File to write the bundle to instead of stdout",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			format := c.String("format")
+			if format == "" && c.String("output") == "" {
+				format = "json"
+			}
+			format, err = config.Format(format, c.String("output"))
+			if err != nil {
+				return err
+			}
+
+			bundle, err := cfg.Export(c.StringSlice("alias"), c.StringSlice("tag"))
+			if err != nil {
+				return err
+			}
+
+			data, err := config.EncodeBundle(bundle, format)
+			if err != nil {
+				return err
+			}
+
+			if c.String("output") == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			return os.WriteFile(c.String("output"), data, 0644)
+		},
+	}
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// userFlagNames are the names of the flags providing user profile data
+var userFlagNames = []string{
+	"name",
+	"email",
+	"add-email",
+	"remove-email",
+	"alias",
+	"add-tag",
+	"remove-tag",
+	"signing-key",
+	"signing-format",
+	"sign-commits",
+	"sign-tags",
+	"allowed-signers",
+	"ssh-key",
+	"git-config",
+	"unset-git-config",
+	"allowed-domain",
+	"clear-allowed-domains",
+	"clear-alias",
+	"clear-signing-key",
+	"clear-signing-format",
+	"clear-allowed-signers",
+	"clear-ssh-key",
+}
+
+// clearFlagFields maps the flags clearing optional user profile fields to the flags setting them
+var clearFlagFields = map[string]string{
+	"clear-alias":           "alias",
+	"clear-signing-key":     "signing-key",
+	"clear-signing-format":  "signing-format",
+	"clear-allowed-signers": "allowed-signers",
+	"clear-ssh-key":         "ssh-key",
+}
+
+// userFlags returns the flags used to provide user profile data without prompts
+func userFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "Git user name",
+		},
+		&cli.StringFlag{
+			Name:  "email",
+			Usage: "Git user email, the primary email of the profile",
+		},
+		&cli.StringSliceFlag{
+			Name:  "add-email",
+			Usage: "Further email of the profile, e.g. a noreply address, can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "alias",
+			Usage: "User alias",
+		},
+		&cli.StringSliceFlag{
+			Name:  "add-tag",
+			Usage: "Tag grouping the profile, e.g. by client, can be repeated",
+		},
+		&cli.StringFlag{
+			Name:    "signing-key",
+			Aliases: []string{"gpg-key"},
+			Usage:   "Signing key: GPG key ID, SSH key path or X.509 certificate ID",
+		},
+		&cli.StringFlag{
+			Name:  "signing-format",
+			Usage: "Signing format: openpgp, ssh or x509",
+		},
+		&cli.BoolFlag{
+			Name:  "sign-commits",
+			Usage: "Sign all commits (commit.gpgsign)",
+		},
+		&cli.BoolFlag{
+			Name:  "sign-tags",
+			Usage: "Sign all tags (tag.gpgsign)",
+		},
+		&cli.StringFlag{
+			Name:  "allowed-signers",
+			Usage: "Allowed signers file used to verify SSH signatures (gpg.ssh.allowedSignersFile)",
+		},
+		&cli.StringFlag{
+			Name:  "ssh-key",
+			Usage: "SSH identity file used to authenticate against remotes (core.sshCommand)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "git-config",
+			Usage: "Extra git config option as key=value, can be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:  "allowed-domain",
+			Usage: "Email domain allowed for this user instead of the globally allowed ones, can be repeated",
+		},
+		&cli.BoolFlag{
+			Name:  "gpg",
+			Value: false,
+			Usage: "Prompt for signing key",
+		},
+	}
+}
+
+// hasUserFlags returns if any of the user profile data flags was provided
+func hasUserFlags(c *cli.Context) bool {
+	for _, name := range userFlagNames {
+		if c.IsSet(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// unsetGitConfigFlag returns the flag used to remove extra git config options from a user profile
+func unsetGitConfigFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "unset-git-config",
+		Usage: "Remove an extra git config option by key, can be repeated",
+	}
+}
+
+// clearFlags returns the flags used to clear optional fields of a user profile
+func clearFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "clear-alias",
+			Usage: "Remove the alias",
+		},
+		&cli.BoolFlag{
+			Name:    "clear-signing-key",
+			Aliases: []string{"clear-gpg"},
+			Usage:   "Remove the signing key",
+		},
+		&cli.BoolFlag{
+			Name:  "clear-signing-format",
+			Usage: "Remove the signing format, i.e. use git's default",
+		},
+		&cli.BoolFlag{
+			Name:  "clear-allowed-signers",
+			Usage: "Remove the allowed signers file",
+		},
+		&cli.BoolFlag{
+			Name:  "clear-ssh-key",
+			Usage: "Remove the SSH key",
+		},
+		&cli.BoolFlag{
+			Name:  "clear-allowed-domains",
+			Usage: "Remove the allowed email domains, i.e. use the globally allowed ones",
+		},
+	}
+}
+
+// removeEmailFlag returns the flag used to remove further emails from a user profile
+func removeEmailFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "remove-email",
+		Usage: "Remove a further email, can be repeated",
+	}
+}
+
+// removeTagFlag returns the flag used to remove tags from a user profile
+func removeTagFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "remove-tag",
+		Usage: "Remove a tag, can be repeated",
+	}
+}
+
+// tagFilterFlag returns the flag restricting a command to users with certain tags
+func tagFilterFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:  "tag",
+		Usage: "Only consider users with this tag, can be repeated to require several tags",
+	}
+}
+
+// allowedDomains returns the normalized domains of the allowed domain flag
+func allowedDomains(c *cli.Context) []string {
+	var domains []string
+	for _, domain := range c.StringSlice("allowed-domain") {
+		domains = append(domains, models.NormalizeDomain(domain))
+	}
+	return domains
+}
+
+// parseGitConfig parses key=value pairs of extra git config options
+func parseGitConfig(pairs []string) (map[string]string, error) {
+	gitConfig := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		gitConfig[pair[:i]] = pair[i+1:]
+	}
+	return gitConfig, nil
+}
+
+// userPatchFromFlags returns the changes to a user profile provided via flags
+func userPatchFromFlags(c *cli.Context) (*models.UserPatch, error) {
+	patch := &models.UserPatch{}
+	fieldPointers := map[string]**string{
+		"name":            &patch.Name,
+		"email":           &patch.Email,
+		"alias":           &patch.Alias,
+		"signing-key":     &patch.SigningKey,
+		"signing-format":  &patch.SigningFormat,
+		"allowed-signers": &patch.AllowedSignersFile,
+		"ssh-key":         &patch.SSHKey,
+	}
+	for name, field := range fieldPointers {
+		if c.IsSet(name) {
+			value := c.String(name)
+			*field = &value
+		}
+	}
+
+	for clearName, name := range clearFlagFields {
+		if !c.Bool(clearName) {
+			continue
+		}
+		if c.IsSet(name) {
+			return nil, fmt.Errorf("--%s and --%s can not be combined", name, clearName)
+		}
+		empty := ""
+		*fieldPointers[name] = &empty
+	}
+
+	for name, field := range map[string]**bool{
+		"sign-commits": &patch.SignCommits,
+		"sign-tags":    &patch.SignTags,
+	} {
+		if c.IsSet(name) {
+			value := c.Bool(name)
+			*field = &value
+		}
+	}
+
+	patch.AddEmails = c.StringSlice("add-email")
+	patch.RemoveEmails = c.StringSlice("remove-email")
+	patch.AddTags = c.StringSlice("add-tag")
+	patch.RemoveTags = c.StringSlice("remove-tag")
+
+	if c.IsSet("allowed-domain") && c.Bool("clear-allowed-domains") {
+		return nil, fmt.Errorf("--allowed-domain and --clear-allowed-domains can not be combined")
+	}
+	if c.IsSet("allowed-domain") || c.Bool("clear-allowed-domains") {
+		domains := allowedDomains(c)
+		patch.AllowedDomains = &domains
+	}
+
+	if c.IsSet("git-config") {
+		gitConfig, err := parseGitConfig(c.StringSlice("git-config"))
+		if err != nil {
+			return nil, err
+		}
+		patch.SetGitConfig = gitConfig
+	}
+	patch.UnsetGitConfig = c.StringSlice("unset-git-config")
+
+	return patch, nil
+}
@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+	"github.com/matsuyoshi30/gitsu/internal/utils"
+
+	"github.com/urfave/cli/v2"
+)
+
+// hookMarker identifies hooks installed by gitsu
+const hookMarker = "# gitsu identity guard"
+
+// hookTemplate is the hook script installed into a repository. If the gitsu executable is gone, the hook warns and
+// lets the commit through instead of blocking all commits
+const hookTemplate = `#!/bin/sh
+%s
+gitsu=%s
+if ! command -v "$gitsu" >/dev/null 2>&1; then
+	echo "warning: $gitsu not found, skipping the gitsu identity check" >&2
+	exit 0
+fi
+exec "$gitsu"%s verify
+`
+
+// globalHookTemplate is the hook script installed into the global hooks directory. As git ignores the hooks of a
+// repository if core.hooksPath is set, it runs the repository hook itself
+const globalHookTemplate = `#!/bin/sh
+%s
+gitsu=%s
+if command -v "$gitsu" >/dev/null 2>&1; then
+	"$gitsu"%s verify || exit 1
+else
+	echo "warning: $gitsu not found, skipping the gitsu identity check" >&2
+fi
+%s`
+
+// chainHookTemplate is the hook script installed for all other hooks into a global hooks directory gitsu configured,
+// so that the repository hooks keep running
+const chainHookTemplate = `#!/bin/sh
+%s
+%s`
+
+// runRepositoryHookTemplate runs the hook of the repository with the same name
+const runRepositoryHookTemplate = `hook="$(git rev-parse --git-common-dir)/hooks/%s"
+if [ -x "$hook" ]; then
+	exec "$hook" "$@"
+fi
+`
+
+// guardedHookNames are the hooks that can run 'gitsu verify'
+var guardedHookNames = []string{"pre-commit", "pre-push"}
+
+// chainedHookNames are the hooks run from a global hooks directory gitsu configured
+var chainedHookNames = []string{
+	"applypatch-msg", "pre-applypatch", "post-applypatch", "pre-commit", "pre-merge-commit", "prepare-commit-msg",
+	"commit-msg", "post-commit", "pre-rebase", "post-checkout", "post-merge", "pre-push", "pre-auto-gc",
+	"post-rewrite", "sendemail-validate", "fsmonitor-watchman", "post-index-change", "reference-transaction",
+	"push-to-checkout",
+}
+
+// HookCommand returns the definition for the 'gitsu hook' command
+func HookCommand() *cli.Command {
+	flags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "global",
+			Value: false,
+			Usage: "Use the global hooks directory (core.hooksPath) instead of the current repository",
+		},
+		&cli.BoolFlag{
+			Name:  "pre-push",
+			Value: false,
+			Usage: "Also guard pushes",
+		},
+	}
+
+	return &cli.Command{
+		Name:  "hook",
+		Usage: "Manage hooks blocking commits made with the wrong user",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "install",
+				Usage: "Install a pre-commit hook running 'gitsu verify'",
+				Flags: append(flags,
+					&cli.BoolFlag{
+						Name:  "force",
+						Value: false,
+						Usage: "Overwrite existing hooks not installed by gitsu",
+					},
+					&cli.BoolFlag{
+						Name:  "set-hooks-path",
+						Value: false,
+						Usage: "With --global, set the global core.hooksPath to a directory of gitsu if it is not set",
+					},
+				),
+				Action: func(c *cli.Context) error {
+					dir, owned, err := hooksDir(c.Bool("global"), c.Bool("set-hooks-path"))
+					if err != nil {
+						return err
+					}
+
+					for _, name := range hookNames(c) {
+						path := filepath.Join(dir, name)
+						if !c.Bool("force") && utils.FileExists(path) && !isGitsuHook(path) {
+							return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+						}
+					}
+
+					err = os.MkdirAll(dir, 0755)
+					if err != nil {
+						return err
+					}
+
+					for _, name := range hookNames(c) {
+						path := filepath.Join(dir, name)
+						err = utils.WriteFileAtomic(path, []byte(hookScript(name, c.Bool("global"))), 0755)
+						if err != nil {
+							return err
+						}
+						fmt.Printf("Installed %s\n", path)
+					}
+
+					// The hooks directory replaces the ones of all repositories, so all their hooks have to be run
+					if owned {
+						for _, name := range chainedHookNames {
+							path := filepath.Join(dir, name)
+							if utils.FileExists(path) {
+								continue
+							}
+
+							err = utils.WriteFileAtomic(path, []byte(chainHookScript(name)), 0755)
+							if err != nil {
+								return err
+							}
+						}
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "uninstall",
+				Usage: "Remove hooks installed by gitsu",
+				Flags: flags,
+				Action: func(c *cli.Context) error {
+					dir, owned, err := hooksDir(c.Bool("global"), false)
+					if err != nil || dir == "" {
+						return err
+					}
+
+					for _, name := range hookNames(c) {
+						path := filepath.Join(dir, name)
+						if !isGitsuGuardHook(path) {
+							continue
+						}
+
+						if owned {
+							err = utils.WriteFileAtomic(path, []byte(chainHookScript(name)), 0755)
+						} else {
+							err = os.Remove(path)
+						}
+						if err != nil {
+							return err
+						}
+						fmt.Printf("Removed %s\n", path)
+					}
+
+					if owned {
+						return removeHooksDir(dir)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// hooksDir returns the directory to install hooks into and if it is the global hooks directory of gitsu. For global
+// hooks this is the directory configured with the global core.hooksPath option. If it is not set and setHooksPath is
+// true, a directory next to the config file is configured. Repository hooks are rejected if the repository uses the
+// global hooks directory of gitsu, as they would replace the hooks running the repository hooks
+func hooksDir(global, setHooksPath bool) (string, bool, error) {
+	gitsuDir, err := gitsuHooksDir()
+	if err != nil {
+		return "", false, err
+	}
+
+	if !global {
+		err := git.IsInsideWorktree(models.Local)
+		if err != nil {
+			return "", false, err
+		}
+
+		dir, err := git.HooksDir()
+		if err != nil {
+			return "", false, err
+		}
+		if filepath.Clean(dir) == gitsuDir {
+			return "", false, fmt.Errorf("The repository uses the global hooks directory of gitsu %s, use --global to manage the hooks there", gitsuDir)
+		}
+		return dir, false, nil
+	}
+
+	dir, err := git.GetPathConfig("core.hooksPath", models.Global)
+	if err != nil {
+		return "", false, err
+	}
+	if dir != "" {
+		dir, err = resolveHooksPath(dir)
+		if err != nil {
+			return "", false, err
+		}
+		return dir, dir == gitsuDir, nil
+	}
+	if !setHooksPath {
+		return "", false, fmt.Errorf("The global core.hooksPath is not set. Setting it makes git ignore the hooks of all repositories, use --set-hooks-path to let gitsu set it to %s and run the repository hooks from there", gitsuDir)
+	}
+
+	err = git.SetOption("core.hooksPath", gitsuDir, models.Global)
+	if err != nil {
+		return "", false, err
+	}
+
+	fmt.Printf("Setting global core.hooksPath to %s, repository hooks are run from there\n", gitsuDir)
+	return gitsuDir, true, nil
+}
+
+// resolveHooksPath returns the absolute path of a core.hooksPath value. Like git, a relative path is resolved against
+// the top level directory of the repository the hooks run in, so it can only be resolved inside a repository
+func resolveHooksPath(dir string) (string, error) {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir), nil
+	}
+
+	if git.IsInsideWorktree(models.Local) != nil {
+		return "", fmt.Errorf("The global core.hooksPath %s is relative to each repository, run gitsu inside the repository to manage its hooks", dir)
+	}
+
+	topLevel, err := git.TopLevel()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(topLevel, dir), nil
+}
+
+// gitsuHooksDir returns the global hooks directory gitsu configures next to the config file
+func gitsuHooksDir() (string, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "hooks"), nil
+}
+
+// removeHooksDir removes the global hooks directory of gitsu and unsets core.hooksPath once no hook runs 'gitsu verify'
+// anymore. The directory is kept if it contains other files
+func removeHooksDir(dir string) error {
+	for _, name := range chainedHookNames {
+		if isGitsuGuardHook(filepath.Join(dir, name)) {
+			return nil
+		}
+	}
+
+	for _, name := range chainedHookNames {
+		path := filepath.Join(dir, name)
+		if !isGitsuHook(path) {
+			continue
+		}
+
+		err := os.Remove(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		fmt.Printf("Keeping global core.hooksPath %s as it contains other hooks\n", dir)
+		return nil
+	}
+
+	err = os.Remove(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	err = git.UnsetOption("core.hooksPath", models.Global)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unset global core.hooksPath %s\n", dir)
+	return nil
+}
+
+// hookNames returns the names of the hooks selected via flags
+func hookNames(c *cli.Context) []string {
+	if c.Bool("pre-push") {
+		return []string{"pre-commit", "pre-push"}
+	}
+	return []string{"pre-commit"}
+}
+
+// hookScript returns the script of the named hook. The absolute path of the running executable is used so that the
+// hook works without gitsu on PATH, e.g. in GUI clients. A config file selected via --config or GITSU_CONFIG is passed
+// on, as the hook runs without them
+func hookScript(name string, global bool) string {
+	executable := "gitsu"
+	if path, err := os.Executable(); err == nil {
+		executable = path
+	}
+
+	var args string
+	if path := config.CustomPath(); path != "" {
+		args = " --config " + utils.ShellQuote(path)
+	}
+
+	if global {
+		return fmt.Sprintf(globalHookTemplate, hookMarker, utils.ShellQuote(executable), args, fmt.Sprintf(runRepositoryHookTemplate, name))
+	}
+	return fmt.Sprintf(hookTemplate, hookMarker, utils.ShellQuote(executable), args)
+}
+
+// chainHookScript returns the script of a hook that only runs the repository hook with the same name
+func chainHookScript(name string) string {
+	return fmt.Sprintf(chainHookTemplate, hookMarker, fmt.Sprintf(runRepositoryHookTemplate, name))
+}
+
+// isGitsuHook returns if the hook at path was installed by gitsu
+func isGitsuHook(path string) bool {
+	b, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(b), hookMarker)
+}
+
+// isGitsuGuardHook returns if the hook at path was installed by gitsu and runs 'gitsu verify'
+func isGitsuGuardHook(path string) bool {
+	b, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(b), hookMarker) && strings.Contains(string(b), " verify")
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/matsuyoshi30/gitsu/cmd/prompts"
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// importCandidate describes a user found in a git config file
+type importCandidate struct {
+	user   *models.User
+	source string
+}
+
+// ImportCommand returns the definition for the 'gitsu import' command
+func ImportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "Import users from the global git config, its included files and repositories, or from a bundle",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "file",
+				Usage: "Bundle created by 'gitsu export' to import instead of scanning git configs",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Bundle format: json, yaml or toml, defaults to the extension of the file",
+			},
+			&cli.StringFlag{
+				Name:  "strategy",
+				Value: string(config.MergeSkip),
+				Usage: "Handling of users conflicting with existing ones: skip, overwrite or rename (the alias)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "dir",
+				Usage: "Directory to scan for repositories, can be repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "yes",
+				Value: false,
+				Usage: "Import all found users without asking",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.IsSet("file") {
+				return importBundle(c)
+			}
+
+			candidates, err := collectImportCandidates(c.StringSlice("dir"))
+			if err != nil {
+				return err
+			}
+
+			if len(candidates) == 0 {
+				fmt.Println("No users found")
+				return nil
+			}
+
+			unlock, err := config.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			cfg, err := config.ReadOrNew()
+			if err != nil {
+				return err
+			}
+
+			var imported int
+			for _, candidate := range candidates {
+				user := candidate.user
+				if !c.Bool("yes") {
+					selection, _, err := prompts.SelectionCustom(
+						fmt.Sprintf("Import %s (signing key %s) from %s?", user.Format(0), orDash(user.SigningKey), candidate.source),
+						[]string{"Yes", "No"},
+					)
+					if err != nil {
+						return err
+					}
+					if selection == 1 {
+						continue
+					}
+
+					user.Alias, err = prompts.Input("User alias, leave empty for no alias")
+					if err != nil {
+						return err
+					}
+				}
+
+				err = cfg.AddUser(user)
+				if err != nil {
+					fmt.Printf("Skipping %s: %s\n", user.Format(0), err)
+					continue
+				}
+
+				fmt.Printf("Importing %s\n", user.Format(0))
+				imported++
+			}
+
+			if imported == 0 {
+				return nil
+			}
+			return config.Write(cfg)
+		},
+	}
+}
+
+// importBundle imports the users of the bundle file selected via flags
+func importBundle(c *cli.Context) error {
+	strategy, err := config.ParseMergeStrategy(c.String("strategy"))
+	if err != nil {
+		return err
+	}
+
+	format, err := config.Format(c.String("format"), c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	bundle, err := config.DecodeBundle(data, format)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := config.ReadOrNew()
+	if err != nil {
+		return err
+	}
+
+	for i := range bundle.Users {
+		user := &bundle.Users[i]
+		result, oldAlias, err := cfg.ImportUser(user, strategy)
+		if err != nil {
+			fmt.Printf("Skipping %s: %s\n", user.Format(0), err)
+			continue
+		}
+		fmt.Printf("Importing %s (%s)\n", user.Format(0), result)
+
+		if result == config.Overwritten {
+			err = syncBindings(cfg, oldAlias, user)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return config.Write(cfg)
+}
+
+// collectImportCandidates returns the distinct users defined in the global git config, the files it includes and
+// the local config of all repositories below the directories
+func collectImportCandidates(dirs []string) ([]importCandidate, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]string{"": "global git config"}
+	order := []string{""}
+	add := func(path, source string) {
+		if _, ok := paths[path]; !ok {
+			paths[path] = source
+			order = append(order, path)
+		}
+	}
+
+	includes, err := git.IncludePaths(home)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range includes {
+		add(path, path)
+	}
+
+	for _, dir := range dirs {
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			// Skip unreadable directories instead of aborting the whole import
+			if err != nil && d != nil && d.IsDir() && path != dir {
+				fmt.Printf("Skipping %s: %s\n", path, err)
+				return filepath.SkipDir
+			}
+			if err != nil || !d.IsDir() || d.Name() != ".git" {
+				return err
+			}
+
+			add(filepath.Join(path, "config"), filepath.Dir(path))
+			return filepath.SkipDir
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var candidates []importCandidate
+	for _, path := range order {
+		if path != "" && !fileReadable(path) {
+			continue
+		}
+
+		user, err := git.ReadUser(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key := user.Name + "\x00" + user.Email + "\x00" + user.SigningKey
+		if user.Name == "" || user.Email == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		candidates = append(candidates, importCandidate{user: user, source: paths[path]})
+	}
+	return candidates, nil
+}
+
+// fileReadable returns if the file at path exists and can be read
+func fileReadable(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	return true
+}
@@ -12,18 +12,19 @@ import (
 
 func InitCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "init",
-		Aliases: []string{"i"},
-		Usage:   "Initialize user config by providing an alias",
+		Name:      "init",
+		Aliases:   []string{"i"},
+		Usage:     "Initialize user config by providing an alias",
+		ArgsUsage: "[ID, alias or position]",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "global",
 				Value: false,
 				Usage: "Set git user globally",
 			},
+			emailFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			alias := c.Args().First()
 
 			cfg, err := config.Read()
 			if err != nil {
@@ -46,12 +47,17 @@ func InitCommand() *cli.Command {
 				return err
 			}
 
-			if alias == "" {
+			if c.Args().First() == "" {
 				defaultUser, err := cfg.SelectDefaultUser()
 				if err != nil {
 					return err
 				}
 
+				defaultUser, err = selectEmail(c, defaultUser, false)
+				if err != nil {
+					return err
+				}
+
 				err = git.SetConfig(defaultUser, scope)
 				if err != nil {
 					return err
@@ -61,7 +67,17 @@ func InitCommand() *cli.Command {
 				return nil
 			}
 
-			user, err := cfg.SelectUserByAlias(alias)
+			id, err := selectUserID(c, cfg, "Select git user")
+			if err != nil {
+				return err
+			}
+
+			user, err := cfg.SelectUser(id)
+			if err != nil {
+				return err
+			}
+
+			user, err = selectEmail(c, user, false)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/constants"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ListCommand returns the definition for the 'gitsu list' command
+func ListCommand() *cli.Command {
+	return &cli.Command{
+		Name:    "list",
+		Aliases: []string{"l"},
+		Usage:   "List saved user profiles",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "table",
+				Usage: "Output format: table, json, yaml or template",
+			},
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "Go template executed for every user if the format is 'template', e.g. '{{ .Alias }}: {{ .Email }}'",
+			},
+			tagFilterFlag(),
+		},
+		Action: func(c *cli.Context) error {
+			cfg, err := config.Read()
+			if err != nil {
+				return err
+			}
+
+			users := cfg.FilterUsers(c.StringSlice("tag"))
+			switch c.String("format") {
+			case "table":
+				if len(users) == 0 {
+					fmt.Println("No users")
+					return nil
+				}
+				return writeUserTable(os.Stdout, cfg, users)
+			case "json":
+				b, err := json.MarshalIndent(users, "", constants.JsonIndent)
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(os.Stdout, string(b))
+				return err
+			case "yaml":
+				return yaml.NewEncoder(os.Stdout).Encode(users)
+			case "template":
+				if c.String("template") == "" {
+					return fmt.Errorf("the template format requires the --template flag")
+				}
+				return writeUserTemplate(os.Stdout, c.String("template"), users)
+			default:
+				return fmt.Errorf("unknown format %s", c.String("format"))
+			}
+		},
+	}
+}
+
+// writeUserTable writes the users as an aligned table. The position column holds the position commands accept to
+// select a user
+func writeUserTable(out io.Writer, cfg *config.Config, users []models.User) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tID\tALIAS\tNAME\tEMAIL\tTAGS\tSIGNING KEY\tADDED\tMODIFIED")
+	for _, user := range users {
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			cfg.Position(user.ID),
+			user.ID,
+			orDash(user.Alias),
+			user.Name,
+			strings.Join(user.AllEmails(), ", "),
+			orDash(strings.Join(user.Tags, ", ")),
+			orDash(user.SigningKey),
+			formatTime(user.AddedAt),
+			formatTime(user.ModifiedAt),
+		)
+	}
+	return w.Flush()
+}
+
+// writeUserTemplate executes the template for every user, each followed by a newline
+func writeUserTemplate(out io.Writer, rawTemplate string, users []models.User) error {
+	t, err := template.New("list").Parse(rawTemplate)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		err = t.Execute(out, user)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+	}
+	return nil
+}
+
+// orDash returns the value or a dash if the value is empty
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// formatTime formats a timestamp for tables or returns a dash for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -12,10 +12,18 @@ import (
 
 func ModifyCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "modify",
-		Aliases: []string{"m"},
-		Usage:   "Modify existing user",
+		Name:      "modify",
+		Aliases:   []string{"m"},
+		Usage:     "Modify existing user",
+		ArgsUsage: "[ID, alias or position]",
+		Flags:     append(append(userFlags(), unsetGitConfigFlag(), removeEmailFlag(), removeTagFlag()), clearFlags()...),
 		Action: func(c *cli.Context) error {
+			unlock, err := config.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			cfg, err := config.Read()
 			if err != nil {
 				return err
@@ -27,36 +35,39 @@ func ModifyCommand() *cli.Command {
 				return nil
 			}
 
-			index, _, err := prompts.SelectionCustom("Select git user", list)
+			id, err := selectUserID(c, cfg, "Select git user")
 			if err != nil {
 				return err
 			}
 
-			name, err := prompts.Input("New git user name, leave empty for no change")
+			user, err := cfg.SelectUser(id)
 			if err != nil {
 				return err
 			}
 
-			email, err := prompts.Input("New git email address, leave empty for no change")
-			if err != nil {
-				return err
-			}
-
-			var keyID string
-			if c.Bool("gpg") {
-				keyID, err = prompts.Input("GPG key ID")
+			var patch *models.UserPatch
+			if hasUserFlags(c) {
+				patch, err = userPatchFromFlags(c)
+				if err != nil {
+					return err
+				}
+			} else {
+				patch, err = promptUserPatch(c, cfg, user)
 				if err != nil {
 					return err
 				}
 			}
 
-			alias, err := prompts.Input("User alias, leave empty for no alias")
+			oldAlias := user.Alias
+			err = cfg.ModifyUser(id, patch)
 			if err != nil {
 				return err
 			}
+			if patch.SigningKey != nil || patch.SigningFormat != nil {
+				warnSigningKey(user)
+			}
 
-			user := models.NewUser(name, email, alias, keyID)
-			err = cfg.ModifyUser(index, user)
+			err = syncBindings(cfg, oldAlias, user)
 			if err != nil {
 				return err
 			}
@@ -65,3 +76,56 @@ func ModifyCommand() *cli.Command {
 		},
 	}
 }
+
+// promptUserPatch asks for the modified user profile data field by field. The prompts are pre-filled with the current
+// values, emptying an optional field removes it
+func promptUserPatch(c *cli.Context, cfg *config.Config, user *models.User) (*models.UserPatch, error) {
+	notEmpty := func(s string) error {
+		if s == "" {
+			return fmt.Errorf("must not be empty")
+		}
+		return nil
+	}
+
+	name, err := prompts.InputWithDefault("Git user name", user.Name, notEmpty)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := cfg.EmailPolicyFor(user)
+	email, err := prompts.InputWithDefault("Git email address", user.Email, policy.Validate)
+	if err != nil {
+		return nil, err
+	}
+
+	alias, err := prompts.InputWithDefault("User alias, empty for no alias", user.Alias, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := user.SigningKey
+	if c.Bool("gpg") || user.SigningKey != "" {
+		keyID, err = prompts.InputWithDefault("Signing key (GPG key ID or SSH key path), empty for no key", user.SigningKey, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	patch := &models.UserPatch{}
+	for _, field := range []struct {
+		value   string
+		current string
+		patch   **string
+	}{
+		{name, user.Name, &patch.Name},
+		{email, user.Email, &patch.Email},
+		{alias, user.Alias, &patch.Alias},
+		{keyID, user.SigningKey, &patch.SigningKey},
+	} {
+		if field.value != field.current {
+			value := field.value
+			*field.patch = &value
+		}
+	}
+	return patch, nil
+}
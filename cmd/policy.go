@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// PolicyCommand returns the definition for the 'gitsu policy' command
+func PolicyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "policy",
+		Usage: "Manage the email policy checked when users are added, modified or imported",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "show",
+				Usage: "Show the global email policy and the users' allowed domains",
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Read()
+					if err != nil {
+						return err
+					}
+
+					printPolicy(cfg)
+					return nil
+				},
+			},
+			{
+				Name:  "set",
+				Usage: "Change the global email policy",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "allowed-domain",
+						Usage: "Allowed email domain, subdomains are allowed as well, can be repeated",
+					},
+					&cli.BoolFlag{
+						Name:  "clear-allowed-domains",
+						Usage: "Allow all email domains",
+					},
+					&cli.BoolFlag{
+						Name:  "allow-placeholders",
+						Usage: "Accept placeholder emails like you@example.com, disable with --allow-placeholders=false",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.IsSet("allowed-domain") && c.Bool("clear-allowed-domains") {
+						return fmt.Errorf("--allowed-domain and --clear-allowed-domains can not be combined")
+					}
+
+					unlock, err := config.Lock()
+					if err != nil {
+						return err
+					}
+					defer unlock()
+
+					cfg, err := config.ReadOrNew()
+					if err != nil {
+						return err
+					}
+
+					policy := models.EmailPolicy{}
+					if cfg.EmailPolicy != nil {
+						policy = *cfg.EmailPolicy
+					}
+					if c.IsSet("allowed-domain") || c.Bool("clear-allowed-domains") {
+						policy.AllowedDomains = allowedDomains(c)
+					}
+					if c.IsSet("allow-placeholders") {
+						policy.AllowPlaceholders = c.Bool("allow-placeholders")
+					}
+
+					cfg.EmailPolicy = &policy
+					if len(policy.AllowedDomains) == 0 && !policy.AllowPlaceholders {
+						cfg.EmailPolicy = nil
+					}
+
+					// Existing users are kept, but violations of the new policy are reported
+					for i := range cfg.Users {
+						user := &cfg.Users[i]
+						err = user.ValidateEmails(cfg.EmailPolicyFor(user))
+						if err != nil {
+							fmt.Printf("Warning: %s violates the policy: %s\n", user.Format(0), err)
+						}
+					}
+
+					err = config.Write(cfg)
+					if err != nil {
+						return err
+					}
+
+					printPolicy(cfg)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// printPolicy prints the global email policy and the allowed domains of users replacing the global ones
+func printPolicy(cfg *config.Config) {
+	var policy models.EmailPolicy
+	if cfg.EmailPolicy != nil {
+		policy = *cfg.EmailPolicy
+	}
+
+	domains := "any"
+	if len(policy.AllowedDomains) > 0 {
+		domains = strings.Join(policy.AllowedDomains, ", ")
+	}
+	placeholders := "rejected"
+	if policy.AllowPlaceholders {
+		placeholders = "allowed"
+	}
+
+	fmt.Printf("Allowed domains:       %s\n", domains)
+	fmt.Printf("Placeholder emails:    %s\n", placeholders)
+	for _, user := range cfg.Users {
+		if len(user.AllowedDomains) > 0 {
+			fmt.Printf("Allowed domains of %s: %s\n", user.Format(0), strings.Join(user.AllowedDomains, ", "))
+		}
+	}
+}
@@ -6,7 +6,12 @@ import (
 	"github.com/manifoldco/promptui"
 )
 
+// Input runs an input prompt and returns the entered value
 func Input(label string) (string, error) {
+	if err := requireTerminal(label); err != nil {
+		return "", err
+	}
+
 	i := &promptui.Prompt{
 		Label:  label,
 		Stdout: &fixes.BellSkipper{},
@@ -14,7 +19,12 @@ func Input(label string) (string, error) {
 	return i.Run()
 }
 
+// InputWithValidation runs an input prompt that only accepts values passing the validation function
 func InputWithValidation(label string, v promptui.ValidateFunc) (string, error) {
+	if err := requireTerminal(label); err != nil {
+		return "", err
+	}
+
 	i := &promptui.Prompt{
 		Label:    label,
 		Validate: v,
@@ -22,3 +32,20 @@ func InputWithValidation(label string, v promptui.ValidateFunc) (string, error)
 	}
 	return i.Run()
 }
+
+// InputWithDefault runs an input prompt pre-filled with an editable default value. The validation function is
+// optional
+func InputWithDefault(label, value string, v promptui.ValidateFunc) (string, error) {
+	if err := requireTerminal(label); err != nil {
+		return "", err
+	}
+
+	i := &promptui.Prompt{
+		Label:     label,
+		Default:   value,
+		AllowEdit: true,
+		Validate:  v,
+		Stdout:    &fixes.BellSkipper{},
+	}
+	return i.Run()
+}
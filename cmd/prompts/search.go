@@ -0,0 +1,68 @@
+package prompts
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightStart = "\033[1;4m"
+	highlightEnd   = "\033[22;24m"
+)
+
+// fuzzyMatch returns if every whitespace separated term of the query occurs in the text as a subsequence, i.e. with
+// its characters in order but not necessarily adjacent. Matching is case-insensitive
+func fuzzyMatch(query, text string) bool {
+	for _, term := range strings.Fields(query) {
+		if matchPositions(term, text) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPositions returns the indexes of the runes of text matching the runes of term as a subsequence, or nil if term
+// is not a subsequence of text
+func matchPositions(term, text string) []int {
+	query := []rune(strings.ToLower(term))
+	if len(query) == 0 {
+		return nil
+	}
+
+	var positions []int
+	for i, r := range []rune(text) {
+		if unicode.ToLower(r) == query[len(positions)] {
+			positions = append(positions, i)
+			if len(positions) == len(query) {
+				return positions
+			}
+		}
+	}
+	return nil
+}
+
+// highlightMatches highlights the runes of text matching the terms of the query. Terms that only match the keywords
+// of an item but not its text are not highlighted
+func highlightMatches(query, text string) string {
+	matched := make(map[int]bool)
+	for _, term := range strings.Fields(query) {
+		for _, i := range matchPositions(term, text) {
+			matched[i] = true
+		}
+	}
+	if len(matched) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(text) {
+		if matched[i] && !matched[i-1] {
+			b.WriteString(highlightStart)
+		}
+		b.WriteRune(r)
+		if matched[i] && !matched[i+1] {
+			b.WriteString(highlightEnd)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package prompts
+
+import "testing"
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  bool
+	}{
+		{"", "[work] John Doe <john@corp.dev>", true},
+		{"work", "[work] John Doe <john@corp.dev>", true},
+		{"jdc", "[work] John Doe <john@corp.dev>", true},
+		{"JOHN", "[work] John Doe <john@corp.dev>", true},
+		{"work corp", "[work] John Doe <john@corp.dev>", true},
+		{"work oss", "[work] John Doe <john@corp.dev>", false},
+		{"dj", "[work] John Doe <john@corp.dev>", true},
+		{"vedpr", "[work] John Doe <john@corp.dev>", false},
+		{"über", "[müller] Jürgen Über <j@corp.dev>", true},
+		{"x", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			if got := fuzzyMatch(test.query, test.text); got != test.want {
+				t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", test.query, test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		want  string
+	}{
+		{"", "John", "John"},
+		{"jo", "John", highlightStart + "Jo" + highlightEnd + "hn"},
+		{"jn", "John", highlightStart + "J" + highlightEnd + "oh" + highlightStart + "n" + highlightEnd},
+		{"oss", "John", "John"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			if got := highlightMatches(test.query, test.text); got != test.want {
+				t.Errorf("highlightMatches(%q, %q) = %q, want %q", test.query, test.text, got, test.want)
+			}
+		})
+	}
+}
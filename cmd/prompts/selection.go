@@ -1,11 +1,16 @@
 package prompts
 
 import (
+	"text/template"
+
 	"github.com/matsuyoshi30/gitsu/internal/fixes"
 
 	"github.com/manifoldco/promptui"
 )
 
+// PageSize is the number of items selection prompts show before scrolling
+var PageSize = 5
+
 var CustomTemplate = &promptui.SelectTemplates{
 	Label:    "{{ . }}",
 	Active:   "▶ {{ . | cyan }}",
@@ -15,9 +20,14 @@ var CustomTemplate = &promptui.SelectTemplates{
 
 // Selection runs a selection prompt and returns the index and value of the selected item
 func Selection(label string, items []string) (int, string, error) {
+	if err := requireTerminal(label); err != nil {
+		return -1, "", err
+	}
+
 	s := promptui.Select{
 		Label:  label,
 		Items:  items,
+		Size:   PageSize,
 		Stdout: &fixes.BellSkipper{},
 	}
 	return s.Run()
@@ -25,11 +35,54 @@ func Selection(label string, items []string) (int, string, error) {
 
 // SelectionCustom runs a selection prompt with custom template and returns the index and value of the selected item
 func SelectionCustom(label string, items []string) (int, string, error) {
+	if err := requireTerminal(label); err != nil {
+		return -1, "", err
+	}
+
 	s := promptui.Select{
 		Label:     label,
 		Items:     items,
+		Size:      PageSize,
 		Stdout:    &fixes.BellSkipper{},
 		Templates: CustomTemplate,
 	}
 	return s.Run()
 }
+
+// SelectionSearch runs a selection prompt with custom template that filters the items while typing and returns the
+// index and value of the selected item. Items match if the query fuzzily matches the item or its keywords, e.g.
+// fields not shown in the item. Matches in the item are highlighted
+func SelectionSearch(label string, items, keywords []string) (int, string, error) {
+	if err := requireTerminal(label); err != nil {
+		return -1, "", err
+	}
+
+	var query string
+	funcMap := template.FuncMap{}
+	for name, f := range promptui.FuncMap {
+		funcMap[name] = f
+	}
+	funcMap["highlight"] = func(item string) string {
+		return highlightMatches(query, item)
+	}
+
+	s := promptui.Select{
+		Label:  label,
+		Items:  items,
+		Size:   PageSize,
+		Stdout: &fixes.BellSkipper{},
+		Templates: &promptui.SelectTemplates{
+			Label:    CustomTemplate.Label,
+			Active:   "▶ {{ highlight . | cyan }}",
+			Inactive: "  {{ highlight . }}",
+			Selected: CustomTemplate.Selected,
+			FuncMap:  funcMap,
+		},
+		Searcher: func(input string, index int) bool {
+			query = input
+			return fuzzyMatch(input, items[index]+" "+keywords[index])
+		},
+		StartInSearchMode: true,
+	}
+	return s.Run()
+}
@@ -0,0 +1,27 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+var (
+	// ErrNotTerminal defines the error when a prompt is required but stdin is not a terminal
+	ErrNotTerminal = errors.New("stdin is not a terminal")
+)
+
+// IsTerminal returns if stdin is attached to a terminal
+func IsTerminal() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
+
+// requireTerminal returns an error naming the missing value if a prompt can not be shown
+func requireTerminal(label string) error {
+	if IsTerminal() {
+		return nil
+	}
+	return fmt.Errorf("%w, cannot prompt for %q: provide the value via command line flags", ErrNotTerminal, label)
+}
@@ -19,14 +19,23 @@ func ResetCommand() *cli.Command {
 	return &cli.Command{
 		Name:    "reset",
 		Aliases: []string{"r"},
-		Usage:   "Remove all saved user profiles",
+		Usage:   "Remove all saved user profiles, or all profiles with the given tags",
+		Flags: []cli.Flag{
+			tagFilterFlag(),
+		},
 		Action: func(c *cli.Context) error {
+			unlock, err := config.Lock()
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			cfg, err := config.Read()
 			if err != nil {
 				return err
 			}
 
-			list := cfg.UserList()
+			list := config.FormatUserList(cfg.FilterUsers(c.StringSlice("tag")))
 			if len(list) == 0 {
 				fmt.Println("No users")
 				return nil
@@ -46,6 +55,14 @@ func ResetCommand() *cli.Command {
 
 			fmt.Println(b)
 
+			rules, bindings := cfg.Reset(c.StringSlice("tag"))
+			for _, rule := range rules {
+				fmt.Printf("The rule %s will be deleted as well\n", rule.Format(0))
+			}
+			for _, binding := range bindings {
+				fmt.Printf("The binding %s will be deleted as well\n", binding.Format(0))
+			}
+
 			selection, _, err := prompts.SelectionCustom(
 				"Delete above profiles?",
 				[]string{"Yes", "No"},
@@ -58,8 +75,18 @@ func ResetCommand() *cli.Command {
 				return nil
 			}
 
-			cfg.Reset()
-			return config.Write(cfg)
+			err = config.Write(cfg)
+			if err != nil {
+				return err
+			}
+
+			for i := range bindings {
+				err = removeBindingInclude(cfg, &bindings[i])
+				if err != nil {
+					return err
+				}
+			}
+			return nil
 		},
 	}
 }
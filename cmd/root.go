@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/matsuyoshi30/gitsu/cmd/prompts"
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/constants"
 
 	"github.com/urfave/cli/v2"
 )
@@ -12,6 +15,30 @@ func Execute() error {
 	app := &cli.App{
 		Name:  "gitsu",
 		Usage: "Easily switch between multiple git users",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				EnvVars: []string{constants.ConfigEnvVar},
+				Usage:   "Path of the config file, the extension selects the format: .json, .yaml or .toml",
+			},
+			&cli.IntFlag{
+				Name:    "page-size",
+				EnvVars: []string{constants.PageSizeEnvVar},
+				Value:   prompts.PageSize,
+				Usage:   "Number of items selection prompts show before scrolling",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if c.Int("page-size") < 1 {
+				return fmt.Errorf("the page size must be positive, got %d", c.Int("page-size"))
+			}
+			prompts.PageSize = c.Int("page-size")
+
+			if c.String("config") != "" {
+				return config.SetPath(c.String("config"))
+			}
+			return nil
+		},
 		Commands: []*cli.Command{
 			DeleteCommand(),
 			ModifyCommand(),
@@ -19,6 +46,20 @@ func Execute() error {
 			ResetCommand(),
 			InitCommand(),
 			AddCommand(),
+			RuleCommand(),
+			AutoCommand(),
+			BindCommand(),
+			UnbindCommand(),
+			CurrentCommand(),
+			ListCommand(),
+			VerifyCommand(),
+			HookCommand(),
+			AuditCommand(),
+			ImportCommand(),
+			ExportCommand(),
+			ConfigCommand(),
+			PolicyCommand(),
+			DoctorCommand(),
 		},
 		Action: func(c *cli.Context) error {
 			action, _, err := prompts.SelectionCustom(
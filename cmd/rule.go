@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// RuleCommand returns the definition for the 'gitsu rule' command
+func RuleCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "rule",
+		Usage: "Manage rules mapping remote URLs to users",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Add a rule, the pattern is a host, a host with path prefix or a glob",
+				ArgsUsage: "<pattern> <alias>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return fmt.Errorf("expected a pattern and an alias, got %d argument(s)", c.NArg())
+					}
+
+					unlock, err := config.Lock()
+					if err != nil {
+						return err
+					}
+					defer unlock()
+
+					cfg, err := config.Read()
+					if err != nil {
+						return err
+					}
+
+					err = cfg.AddRule(models.NewRule(c.Args().Get(0), c.Args().Get(1)))
+					if err != nil {
+						return err
+					}
+
+					return config.Write(cfg)
+				},
+			},
+			{
+				Name:      "delete",
+				Usage:     "Delete a rule",
+				ArgsUsage: "<pattern>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("expected a pattern, got %d argument(s)", c.NArg())
+					}
+
+					unlock, err := config.Lock()
+					if err != nil {
+						return err
+					}
+					defer unlock()
+
+					cfg, err := config.Read()
+					if err != nil {
+						return err
+					}
+
+					err = cfg.DeleteRule(models.NewRule(c.Args().First(), "").Pattern)
+					if err != nil {
+						return err
+					}
+
+					return config.Write(cfg)
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List rules in the order they are matched",
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Read()
+					if err != nil {
+						return err
+					}
+
+					list := cfg.RuleList()
+					if len(list) == 0 {
+						fmt.Println("No rules")
+						return nil
+					}
+
+					for _, rule := range list {
+						fmt.Println(rule)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
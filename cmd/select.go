@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/matsuyoshi30/gitsu/cmd/prompts"
 	"github.com/matsuyoshi30/gitsu/internal/config"
 	"github.com/matsuyoshi30/gitsu/internal/git"
 	"github.com/matsuyoshi30/gitsu/internal/models"
@@ -13,15 +12,18 @@ import (
 
 func SelectCommand() *cli.Command {
 	return &cli.Command{
-		Name:    "select",
-		Aliases: []string{"s"},
-		Usage:   "Select existing user",
+		Name:      "select",
+		Aliases:   []string{"s"},
+		Usage:     "Select existing user",
+		ArgsUsage: "[ID, alias or position]",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:  "global",
 				Value: false,
 				Usage: "Set git user globally",
 			},
+			emailFlag(),
+			tagFilterFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			cfg, err := config.Read()
@@ -35,12 +37,17 @@ func SelectCommand() *cli.Command {
 				return nil
 			}
 
-			index, _, err := prompts.SelectionCustom("Select git user", list)
+			id, err := selectUserID(c, cfg, "Select git user")
 			if err != nil {
 				return err
 			}
 
-			user, err := cfg.SelectUser(index)
+			user, err := cfg.SelectUser(id)
+			if err != nil {
+				return err
+			}
+
+			user, err = selectEmail(c, user, c.Args().First() == "")
 			if err != nil {
 				return err
 			}
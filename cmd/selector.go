@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/cmd/prompts"
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// selectUserID returns the ID of the user addressed by the first positional argument, which is either an ID, an
+// alias or the 1-based position in the user list. Without an argument the user is asked to select one of the users
+// with the tags of the tag flag, grouped by their first tag
+func selectUserID(c *cli.Context, cfg *config.Config, label string) (string, error) {
+	selector := c.Args().First()
+	if selector == "" {
+		tags := c.StringSlice("tag")
+		users := config.GroupUsers(cfg.FilterUsers(tags))
+		if len(users) == 0 {
+			return "", fmt.Errorf("%w: %s", config.ErrNoUserWithTags, strings.Join(tags, ", "))
+		}
+
+		keywords := make([]string, len(users))
+		for i, user := range users {
+			keywords[i] = strings.Join(append(append([]string{user.Alias, user.Name}, user.AllEmails()...), user.Tags...), " ")
+		}
+
+		index, _, err := prompts.SelectionSearch(label, config.FormatUserList(users), keywords)
+		if err != nil {
+			return "", err
+		}
+		return users[index].ID, nil
+	}
+
+	user, err := cfg.SelectUser(selector)
+	if err == nil {
+		return user.ID, nil
+	}
+
+	position, convErr := strconv.Atoi(selector)
+	if convErr != nil {
+		return "", err
+	}
+	if position < 1 || position > len(cfg.Users) {
+		return "", fmt.Errorf("%w: %d", config.ErrUserIndexOutOfBounds, position)
+	}
+
+	return cfg.Users[position-1].ID, nil
+}
+
+// userRef returns the alias of the user or its ID if it has no alias, to address the user in commands
+func userRef(user *models.User) string {
+	if user.Alias == "" {
+		return user.ID
+	}
+	return user.Alias
+}
+
+// emailFlag returns the flag selecting which of the user's emails is applied
+func emailFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "email",
+		Usage: "Email of the profile to apply, defaults to the primary email",
+	}
+}
+
+// selectEmail returns the user with the email selected via the email flag as primary email. Without the flag, users
+// with further emails are asked which one to apply if prompt is set, otherwise the primary email is used
+func selectEmail(c *cli.Context, user *models.User, prompt bool) (*models.User, error) {
+	if email := c.String("email"); email != "" {
+		return user.WithEmail(email)
+	}
+
+	if !prompt || len(user.Emails) == 0 {
+		return user, nil
+	}
+
+	emails := user.AllEmails()
+	index, _, err := prompts.SelectionCustom("Select email", emails)
+	if err != nil {
+		return nil, err
+	}
+	return user.WithEmail(emails[index])
+}
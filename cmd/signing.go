@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/matsuyoshi30/gitsu/cmd/prompts"
+	"github.com/matsuyoshi30/gitsu/internal/gpg"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+// promptSigningKey asks for a signing key. For OpenPGP signing the usable secret keys of the GPG keyring with a user
+// ID matching one of the emails are offered, other keys can be entered manually
+func promptSigningKey(format string, emails []string) (string, error) {
+	const label = "Signing key (GPG key ID or SSH key path)"
+	if !isOpenPGP(format) {
+		return prompts.Input(label)
+	}
+
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		fmt.Printf("Warning: %s, enter the key manually\n", err)
+		return prompts.Input(label)
+	}
+
+	var matching []gpg.Key
+	var items []string
+	for _, key := range keys {
+		for _, email := range emails {
+			if key.Usable() && key.HasEmail(email) {
+				matching = append(matching, key)
+				items = append(items, key.Format())
+				break
+			}
+		}
+	}
+	if len(matching) == 0 {
+		return prompts.Input(label)
+	}
+
+	index, _, err := prompts.SelectionCustom("Select signing key", append(items, "Enter another key"))
+	if err != nil {
+		return "", err
+	}
+	if index == len(matching) {
+		return prompts.Input(label)
+	}
+	return matching[index].ID, nil
+}
+
+// signingKeyProblems returns the reasons why the user's OpenPGP signing key should not be used, e.g. because it is
+// missing from the keyring, expired or revoked. Other signing formats are not checked
+func signingKeyProblems(user *models.User) ([]string, error) {
+	if user.SigningKey == "" || !isOpenPGP(user.SigningFormat) {
+		return nil, nil
+	}
+
+	keys, err := gpg.ListSecretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := gpg.FindKey(keys, user.SigningKey)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	return key.Problems(user.AllEmails()), nil
+}
+
+// warnSigningKey prints warnings if the user's OpenPGP signing key should not be used
+func warnSigningKey(user *models.User) {
+	problems, err := signingKeyProblems(user)
+	if err != nil {
+		fmt.Printf("Warning: the signing key could not be checked: %s\n", err)
+	}
+	for _, problem := range problems {
+		fmt.Printf("Warning: %s\n", problem)
+	}
+}
+
+// isOpenPGP returns if the signing format is OpenPGP, which is git's default
+func isOpenPGP(format string) bool {
+	return format == "" || format == "openpgp"
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/matsuyoshi30/gitsu/internal/config"
+	"github.com/matsuyoshi30/gitsu/internal/git"
+	"github.com/matsuyoshi30/gitsu/internal/models"
+
+	"github.com/urfave/cli/v2"
+)
+
+// VerifyCommand returns the definition for the 'gitsu verify' command
+func VerifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "verify",
+		Usage: "Check that the effective user email matches the rules and bindings, used by the hooks",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "remote",
+				Value: "origin",
+				Usage: "Name of the remote to match against the rules",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Without config the expected user is unknown. The hooks fail closed, so that a moved or deleted config
+			// file does not disable them unnoticed
+			cfg, err := config.Read()
+			if errors.Is(err, config.ErrConfigFileDoesNotExist) {
+				path, _ := config.Path()
+				return fmt.Errorf("%w: %s, the user can not be verified. Restore it or remove the hooks with 'gitsu hook uninstall'", err, path)
+			}
+			if err != nil {
+				return err
+			}
+
+			err = git.IsInsideWorktree(models.Local)
+			if err != nil {
+				return err
+			}
+
+			user, reason, err := expectedUser(cfg, c.String("remote"))
+			if errors.Is(err, config.ErrNoExpectedUser) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			email, err := git.GetEffectiveConfig("user.email")
+			if err != nil {
+				return err
+			}
+
+			if user.HasEmail(email) {
+				return nil
+			}
+
+			return fmt.Errorf(
+				"user.email is %q, but %s expects profile %s\nRun 'gitsu select %s' to fix it",
+				email,
+				reason,
+				user.Format(0),
+				userRef(user),
+			)
+		},
+	}
+}
+
+// expectedUser returns the user the rules and bindings expect for the current repository, together with a
+// description of the rule or binding
+func expectedUser(cfg *config.Config, remote string) (*models.User, string, error) {
+	// Repositories without the remote can still be bound to a directory
+	remoteURL, _ := git.RemoteURL(remote)
+
+	dir, err := git.TopLevel()
+	if err != nil {
+		return nil, "", err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return cfg.ExpectedUser(remoteURL, dir, home)
+}
@@ -3,7 +3,10 @@ module github.com/matsuyoshi30/gitsu
 go 1.15
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d
 	github.com/manifoldco/promptui v0.8.0
+	github.com/mattn/go-isatty v0.0.4
 	github.com/urfave/cli/v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
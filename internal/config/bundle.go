@@ -0,0 +1,202 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+var (
+	ErrUnknownMergeStrategy = errors.New("Unknown merge strategy, expected skip, overwrite or rename")
+)
+
+// Bundle describes the structure of a portable set of user profiles
+type Bundle struct {
+	Version string        `json:"version" yaml:"version" toml:"version"`
+	Users   []models.User `json:"users" yaml:"users" toml:"users"`
+}
+
+// MergeStrategy defines how imported users conflicting with existing users are handled
+type MergeStrategy string
+
+const (
+	// MergeSkip skips conflicting users
+	MergeSkip MergeStrategy = "skip"
+
+	// MergeOverwrite replaces the existing user
+	MergeOverwrite MergeStrategy = "overwrite"
+
+	// MergeRename renames the alias of the imported user if only the alias conflicts
+	MergeRename MergeStrategy = "rename"
+)
+
+// MergeResult describes what happened to an imported user
+type MergeResult int
+
+const (
+	// Added defines that the user was added
+	Added MergeResult = iota
+
+	// Skipped defines that the user was skipped because of a conflict
+	Skipped
+
+	// Overwritten defines that the user replaced an existing user
+	Overwritten
+
+	// Renamed defines that the user was added with a new alias
+	Renamed
+)
+
+// String returns the string representation of the merge result
+func (r MergeResult) String() string {
+	return []string{"added", "skipped", "overwritten", "renamed"}[r]
+}
+
+// ParseMergeStrategy returns the merge strategy with the given name
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	for _, strategy := range []MergeStrategy{MergeSkip, MergeOverwrite, MergeRename} {
+		if string(strategy) == name {
+			return strategy, nil
+		}
+	}
+	return "", ErrUnknownMergeStrategy
+}
+
+// Export returns a bundle of the users with the given aliases, or of all users if no aliases are provided. Only users
+// having all of the given tags are exported
+func (c *Config) Export(aliases, tags []string) (*Bundle, error) {
+	b := &Bundle{
+		Version: strconv.Itoa(CurrentVersion()),
+		Users:   []models.User{},
+	}
+
+	if len(aliases) == 0 {
+		b.Users = append(b.Users, c.FilterUsers(tags)...)
+		return b, nil
+	}
+
+	for _, alias := range aliases {
+		user, err := c.SelectUserByAlias(alias)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, alias)
+		}
+		if user.HasTags(tags) {
+			b.Users = append(b.Users, *user)
+		}
+	}
+	return b, nil
+}
+
+// EncodeBundle serializes the bundle in the given format
+func EncodeBundle(b *Bundle, format string) ([]byte, error) {
+	return marshal(b, format)
+}
+
+// DecodeBundle deserializes a bundle in the given format and migrates it to the current schema version
+func DecodeBundle(data []byte, format string) (*Bundle, error) {
+	raw, err := unmarshalRaw(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = migrate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	b := new(Bundle)
+	err = remarshal(raw, b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ImportUser adds an imported user to the config. Conflicts with existing users are detected with the same rules as
+// for new users and handled according to the merge strategy. Returns the result and the previous alias of an
+// overwritten user. The rename strategy also renames an alias that is the ID of another user
+func (c *Config) ImportUser(user *models.User, strategy MergeStrategy) (MergeResult, string, error) {
+	err := c.validateUserFields(user)
+	if err != nil {
+		return Skipped, "", err
+	}
+
+	if user.AddedAt.IsZero() {
+		user.AddedAt = time.Now()
+	}
+
+	identity, alias := c.conflicts(user, -1)
+	if identity < 0 && alias < 0 && c.idConflict(user, -1) < 0 {
+		err = c.appendImportedUser(user)
+		if err != nil {
+			return Skipped, "", err
+		}
+		return Added, "", nil
+	}
+
+	switch strategy {
+	case MergeOverwrite:
+		// A user can only replace a single existing user
+		if identity >= 0 && alias >= 0 && identity != alias {
+			return Skipped, "", c.isValidUser(user, identity)
+		}
+
+		index := identity
+		if index < 0 {
+			index = alias
+		}
+		// Only the alias is the ID of another user, there is no user to replace
+		if index < 0 {
+			return Skipped, "", c.isValidUser(user, -1)
+		}
+
+		// An imported user without alias keeps the alias of the user it replaces
+		oldAlias := c.Users[index].Alias
+		if user.Alias == "" {
+			user.Alias = oldAlias
+		}
+		err = c.isValidUser(user, index)
+		if err != nil {
+			return Skipped, "", err
+		}
+		c.renameAlias(oldAlias, user.Alias)
+		user.ID = c.Users[index].ID
+		user.Order = c.Users[index].Order
+		c.Users[index] = *user
+		return Overwritten, oldAlias, nil
+	case MergeRename:
+		if identity >= 0 {
+			return Skipped, "", c.isValidUser(user, -1)
+		}
+
+		base := user.Alias
+		for i := 2; alias >= 0 || c.idConflict(user, -1) >= 0; i++ {
+			user.Alias = fmt.Sprintf("%s-%d", base, i)
+			_, alias = c.conflicts(user, -1)
+		}
+		err = c.appendImportedUser(user)
+		if err != nil {
+			return Skipped, "", err
+		}
+		return Renamed, "", nil
+	default:
+		return Skipped, "", c.isValidUser(user, -1)
+	}
+}
+
+// appendImportedUser appends an imported user to the user list. The user keeps its ID unless it is already used
+func (c *Config) appendImportedUser(user *models.User) error {
+	if _, err := c.userIndex(user.ID); user.ID == "" || err == nil {
+		id, err := c.newUserID()
+		if err != nil {
+			return err
+		}
+		user.ID = id
+	}
+	user.Order = c.nextOrder()
+	c.Users = append(c.Users, *user)
+	return nil
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+func TestImportUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     models.User
+		strategy MergeStrategy
+		result   MergeResult
+		oldAlias string
+		wantErr  bool
+		// users are the aliases and IDs of the users after the import
+		users     []models.User
+		ruleAlias string
+	}{
+		{
+			name:     "new user keeps its ID",
+			user:     models.User{ID: "89abcdef", Name: "Jane", Email: "jane@corp.dev", Alias: "jane"},
+			strategy: MergeSkip,
+			result:   Added,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+				{ID: "89abcdef", Alias: "jane"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "new user with used ID gets a new ID",
+			user:     models.User{ID: "0123abcd", Name: "Jane", Email: "jane@corp.dev", Alias: "jane"},
+			strategy: MergeSkip,
+			result:   Added,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+				{Alias: "jane"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "skip conflicting identity",
+			user:     models.User{Name: "John", Email: "john@corp.dev", Alias: "other"},
+			strategy: MergeSkip,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "rename conflicting alias",
+			user:     models.User{Name: "Jane", Email: "jane@corp.dev", Alias: "work"},
+			strategy: MergeRename,
+			result:   Renamed,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+				{Alias: "work-2"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "rename does not resolve conflicting identities",
+			user:     models.User{Name: "John", Email: "john@corp.dev", Alias: "work"},
+			strategy: MergeRename,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "overwrite keeps ID and alias",
+			user:     models.User{ID: "ffffffff", Name: "John", Email: "john@corp.dev"},
+			strategy: MergeOverwrite,
+			result:   Overwritten,
+			oldAlias: "work",
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "overwrite renames rules",
+			user:     models.User{Name: "John", Email: "john@corp.dev", Alias: "client"},
+			strategy: MergeOverwrite,
+			result:   Overwritten,
+			oldAlias: "work",
+			users: []models.User{
+				{ID: "0123abcd", Alias: "client"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "client",
+		},
+		{
+			name:     "overwrite can not replace two users",
+			user:     models.User{Name: "John", Email: "john@corp.dev", Alias: "oss"},
+			strategy: MergeOverwrite,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "skip alias equal to an ID",
+			user:     models.User{Name: "Jane", Email: "jane@corp.dev", Alias: "4567cdef"},
+			strategy: MergeSkip,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "rename alias equal to an ID",
+			user:     models.User{Name: "Jane", Email: "jane@corp.dev", Alias: "4567cdef"},
+			strategy: MergeRename,
+			result:   Renamed,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+				{Alias: "4567cdef-2"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "overwrite with alias equal to an ID",
+			user:     models.User{Name: "John", Email: "john@corp.dev", Alias: "4567cdef"},
+			strategy: MergeOverwrite,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "overwrite without replaced user and alias equal to an ID",
+			user:     models.User{Name: "Jane", Email: "jane@corp.dev", Alias: "4567cdef"},
+			strategy: MergeOverwrite,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+		{
+			name:     "invalid email",
+			user:     models.User{Name: "Jane", Email: "jane@example.com", Alias: "jane"},
+			strategy: MergeOverwrite,
+			result:   Skipped,
+			wantErr:  true,
+			users: []models.User{
+				{ID: "0123abcd", Alias: "work"},
+				{ID: "4567cdef", Alias: "oss"},
+			},
+			ruleAlias: "work",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Config{
+				Users: []models.User{
+					{ID: "0123abcd", Order: 1, Name: "John", Email: "john@corp.dev", Alias: "work"},
+					{ID: "4567cdef", Order: 2, Name: "John", Email: "john@johndoe.dev", Alias: "oss"},
+				},
+				Rules: []models.Rule{{Pattern: "github.com/corp", Alias: "work"}},
+			}
+
+			user := test.user
+			result, oldAlias, err := c.ImportUser(&user, test.strategy)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ImportUser() error = %v, want error %v", err, test.wantErr)
+			}
+			if result != test.result || oldAlias != test.oldAlias {
+				t.Errorf("ImportUser() = %s, %q, want %s, %q", result, oldAlias, test.result, test.oldAlias)
+			}
+
+			if len(c.Users) != len(test.users) {
+				t.Fatalf("got %d users, want %d", len(c.Users), len(test.users))
+			}
+			ids := make(map[string]bool)
+			for i, want := range test.users {
+				got := c.Users[i]
+				if got.Alias != want.Alias || (want.ID != "" && got.ID != want.ID) {
+					t.Errorf("user %d is %s [%s], want %s [%s]", i, got.ID, got.Alias, want.ID, want.Alias)
+				}
+				if got.ID == "" || ids[got.ID] {
+					t.Errorf("user %d: ID %q is empty or not unique", i, got.ID)
+				}
+				ids[got.ID] = true
+				if got.Order != i+1 {
+					t.Errorf("user %d: Order = %d, want %d", i, got.Order, i+1)
+				}
+			}
+
+			if c.Rules[0].Alias != test.ruleAlias {
+				t.Errorf("rule points at [%s], want [%s]", c.Rules[0].Alias, test.ruleAlias)
+			}
+		})
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			c := testConfig()
+			want, err := c.Export(nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := EncodeBundle(want, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := DecodeBundle(data, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertConfigEqual(t, &Config{Users: got.Users}, &Config{Users: want.Users})
+		})
+	}
+}
+
+func TestDecodeBundleMigrates(t *testing.T) {
+	data := []byte(`{"version":"1","users":[{"name":"John","email":"john@corp.dev","alias":"work","gpg_key_id":"ABCDEF12"}]}`)
+
+	b, err := DecodeBundle(data, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Version != strconv.Itoa(CurrentVersion()) {
+		t.Errorf("Version = %s, want %d", b.Version, CurrentVersion())
+	}
+	if len(b.Users) != 1 || b.Users[0].SigningKey != "ABCDEF12" || b.Users[0].ID == "" {
+		t.Errorf("Users = %+v, want one user with an ID and signing key ABCDEF12", b.Users)
+	}
+
+	_, err = DecodeBundle([]byte(`{"version":"99","users":[]}`), "json")
+	if !errors.Is(err, ErrConfigVersionTooNew) {
+		t.Errorf("DecodeBundle() error = %v, want %v", err, ErrConfigVersionTooNew)
+	}
+}
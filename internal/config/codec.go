@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/constants"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnknownFormat = errors.New("Unknown format, expected json, yaml or toml")
+)
+
+// Formats are the supported serialization formats of config files and bundles
+var Formats = []string{"json", "yaml", "toml"}
+
+// Format returns the format of the flag value or, if it is empty, the one matching the file extension
+func Format(format, path string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if format == "yml" {
+			format = "yaml"
+		}
+	}
+
+	for _, f := range Formats {
+		if f == format {
+			return format, nil
+		}
+	}
+	return "", ErrUnknownFormat
+}
+
+// marshal serializes v in the given format
+func marshal(v interface{}, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(v, "", constants.JsonIndent)
+		return append(data, '\n'), err
+	case "yaml":
+		return yaml.Marshal(v)
+	case "toml":
+		buf := &bytes.Buffer{}
+		err := toml.NewEncoder(buf).Encode(v)
+		return buf.Bytes(), err
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// unmarshal deserializes data in the given format into v
+func unmarshal(data []byte, format string, v interface{}) error {
+	switch format {
+	case "json":
+		return json.Unmarshal(data, v)
+	case "yaml":
+		return yaml.Unmarshal(data, v)
+	case "toml":
+		_, err := toml.Decode(string(data), v)
+		return err
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// unmarshalRaw deserializes data in the given format into generic values as produced by encoding/json, which is
+// what the migrations operate on
+func unmarshalRaw(data []byte, format string) (map[string]interface{}, error) {
+	raw := make(map[string]interface{})
+	err := unmarshal(data, format, &raw)
+	if err != nil || format == "json" {
+		return raw, err
+	}
+
+	// YAML and TOML decoders produce other types for nested values, e.g. []map[string]interface{} for TOML tables
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make(map[string]interface{})
+	return normalized, json.Unmarshal(b, &normalized)
+}
@@ -1,12 +1,17 @@
 package config
 
 import (
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/matsuyoshi30/gitsu/internal/constants"
 	"github.com/matsuyoshi30/gitsu/internal/models"
@@ -15,29 +20,71 @@ import (
 
 var (
 	ErrConfigFileDoesNotExist = errors.New("Config file does not exist")
+	ErrConfigVersionTooNew    = errors.New("Config file was written by a newer version of gitsu")
 	ErrUserIndexOutOfBounds   = errors.New("User index out of bounds")
 	ErrNoDefaultUser          = errors.New("No default user")
 	ErrNoUserWithAlias        = errors.New("No user with this alias")
+	ErrNoSuchUser             = errors.New("No user with this ID or alias")
+	ErrNoUserWithTags         = errors.New("No user with these tags")
+	ErrNoRuleWithPattern      = errors.New("No rule with this pattern")
+	ErrNoMatchingRule         = errors.New("No rule matches the remote URL")
+	ErrNoBindingForDir        = errors.New("No binding for this directory")
+	ErrNoMatchingUser         = errors.New("No user matches this identity")
+	ErrNoExpectedUser         = errors.New("No rule or binding applies to this repository")
 )
 
-// Config describes the structure of the JSON based config file
+// Config describes the structure of the config file. Version is the schema version of the file
 type Config struct {
-	Version string        `json:"version"`
-	Users   []models.User `json:"users"`
+	Version  string           `json:"version" yaml:"version" toml:"version"`
+	Users    []models.User    `json:"users" yaml:"users" toml:"users"`
+	Rules    []models.Rule    `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	Bindings []models.Binding `json:"bindings,omitempty" yaml:"bindings,omitempty" toml:"bindings,omitempty"`
+
+	EmailPolicy *models.EmailPolicy `json:"email_policy,omitempty" yaml:"email_policy,omitempty" toml:"email_policy,omitempty"`
+
+	// storedVersion is the schema version the config was stored with before it was migrated
+	storedVersion int
 }
 
-// Dir returns the config directory
+// StoredVersion returns the schema version the config was stored with. It is older than CurrentVersion if the config
+// was migrated when it was read and has not been written since
+func (c *Config) StoredVersion() int {
+	return c.storedVersion
+}
+
+// Dir returns the config directory. It is the directory of the config file selected via SetPath if any, otherwise
+// the gitsu directory in $XDG_CONFIG_HOME if set to an absolute path, or in the user config dir of the OS. A config
+// file created in the user config dir before $XDG_CONFIG_HOME was set keeps being used until one exists there
 func Dir() (string, error) {
+	if customPath != "" {
+		return filepath.Dir(customPath), nil
+	}
+
 	userConfigDir, err := os.UserConfigDir()
+	legacyDir := filepath.Join(userConfigDir, constants.ConfigDir)
+
+	// os.UserConfigDir only considers XDG_CONFIG_HOME on Unix systems other than macOS
+	if xdg := os.Getenv(constants.XdgConfigHome); filepath.IsAbs(xdg) {
+		xdgDir := filepath.Join(xdg, constants.ConfigDir)
+		if err == nil && !utils.FileExists(filepath.Join(xdgDir, constants.ConfigFileName)) &&
+			utils.FileExists(filepath.Join(legacyDir, constants.ConfigFileName)) {
+			return legacyDir, nil
+		}
+		return xdgDir, nil
+	}
+
 	if err != nil {
 		return "", err
 	}
-
-	return filepath.Join(userConfigDir, constants.ConfigDir), nil
+	return legacyDir, nil
 }
 
 // Path returns the config file path
 func Path() (string, error) {
+	if customPath != "" {
+		return customPath, nil
+	}
+
 	configDir, err := Dir()
 	if err != nil {
 		return "", err
@@ -46,84 +93,81 @@ func Path() (string, error) {
 	return filepath.Join(configDir, constants.ConfigFileName), nil
 }
 
-// Exists returns if the config file exists
-func Exists() (bool, error) {
-	configFilePath, err := Path()
+// IncludePath returns the path of the generated git config file that is included for directories bound to the alias
+func IncludePath(alias string) (string, error) {
+	configDir, err := Dir()
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	return utils.FileExists(configFilePath), nil
+	return filepath.Join(configDir, constants.IncludeDir, includeFileName(alias)), nil
 }
 
-// Read reads the config file. Returns the config as a struct or an error if failed to read
-func Read() (*Config, error) {
-	exists, err := Exists()
-	if err != nil {
-		return nil, err
-	}
-	if !exists {
-		return nil, ErrConfigFileDoesNotExist
-	}
+// includeFileName returns the name of the include file of the alias with all characters that are unsafe in file
+// names replaced
+func includeFileName(alias string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, alias)
+	return name + constants.IncludeFileExt
+}
 
-	configFilePath, err := Path()
+// Exists returns if the config file exists
+func Exists() (bool, error) {
+	s, err := currentStorage()
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	b, err := os.ReadFile(configFilePath)
-	if err != nil {
-		return nil, err
-	}
+	return s.Exists()
+}
 
-	c := new(Config)
-	err = json.Unmarshal(b, c)
+// Read reads the config file. Returns the config as a struct or an error if failed to read. Config files of older
+// schema versions are migrated, files of newer versions are rejected
+func Read() (*Config, error) {
+	s, err := currentStorage()
 	if err != nil {
 		return nil, err
 	}
 
-	return c, nil
+	return s.Load()
 }
 
 // Write writes config data to the config file. Returns an error if failed to write data
 func Write(c *Config) error {
-	configDir, err := Dir()
+	s, err := currentStorage()
 	if err != nil {
 		return err
 	}
 
-	// Check if the 'git-su' directory exists. If not, create it
-	dirExists := utils.DirExists(configDir)
-	if !dirExists {
-		err := os.Mkdir(configDir, 0744)
-		if err != nil {
-			return err
-		}
-	}
-
-	configFilePath, err := Path()
+	c.Version = strconv.Itoa(CurrentVersion())
+	err = s.Save(c)
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Create(configFilePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+	c.storedVersion = CurrentVersion()
+	return nil
+}
 
-	data, err := json.MarshalIndent(c, "", constants.JsonIndent)
+// Lock takes an exclusive lock on the config file and returns a function releasing it. It should be held around
+// read-modify-write cycles so that concurrent gitsu processes do not overwrite each other's changes
+func Lock() (func() error, error) {
+	s, err := currentStorage()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = file.Write(data)
-	return err
+	return s.Lock()
 }
 
-// CreateEmptyConfigIfNeeded creates a new empty config file if it does not exits (This is the case when the user uses
-// the tool for the first time)
-func CreateEmptyConfigIfNeeded() (*Config, error) {
+// ReadOrNew reads the config or returns a new empty config if the file does not exist yet (This is the case when the
+// user uses the tool for the first time). The file is created by the caller's Write, so that the first write does not
+// leave an empty config as backup
+func ReadOrNew() (*Config, error) {
 	exists, err := Exists()
 	if err != nil {
 		return nil, err
@@ -132,64 +176,99 @@ func CreateEmptyConfigIfNeeded() (*Config, error) {
 		return Read()
 	}
 
-	c := &Config{
-		Version: "",
+	return &Config{
+		Version: strconv.Itoa(CurrentVersion()),
 		Users:   []models.User{},
-	}
-	return c, Write(c)
+	}, nil
 }
 
-// AddUser adds a new user to the config or returns an error if new user is invalid
+// AddUser adds a new user to the config or returns an error if new user is invalid. The user gets a new ID and is
+// appended to the user list
 func (c *Config) AddUser(user *models.User) error {
-	err := c.isValidUser(user, -1)
+	err := c.validateUserFields(user)
+	if err != nil {
+		return err
+	}
+
+	err = c.isValidUser(user, -1)
 	if err != nil {
 		return err
 	}
 
+	user.ID, err = c.newUserID()
+	if err != nil {
+		return err
+	}
+	user.Order = c.nextOrder()
 	user.AddedAt = time.Now()
 	c.Users = append(c.Users, *user)
 	return nil
 }
 
-// ModifyUser modifies an existing user in the config or returns an error if the index is out of bounds
-func (c *Config) ModifyUser(index int, modifiedUser *models.User) error {
-	if index < 0 || index > len(c.Users)-1 {
-		return ErrUserIndexOutOfBounds
+// ModifyUser modifies the user with the given ID or alias or returns an error if there is no such user
+func (c *Config) ModifyUser(ref string, patch *models.UserPatch) error {
+	index, err := c.userIndex(ref)
+	if err != nil {
+		return err
 	}
 
 	user := c.Users[index]
-	user.Modify(
-		modifiedUser.Name,
-		modifiedUser.Email,
-		modifiedUser.Alias,
-		modifiedUser.GpgKeyID,
-	)
-
-	err := c.isValidUser(&user, index)
+	user.Apply(patch)
+
+	err = c.validateUserFields(&user)
 	if err != nil {
 		return err
 	}
 
+	err = c.isValidUser(&user, index)
+	if err != nil {
+		return err
+	}
+
+	// Rules and bindings need an alias to point at the user
+	if user.Alias == "" {
+		err = c.checkAliasUnused(c.Users[index].Alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	if user.Alias != c.Users[index].Alias && len(c.BindingsByAlias(c.Users[index].Alias)) > 0 {
+		err = c.checkIncludeFileUnused(user.Alias, c.Users[index].Alias)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Keep rules and bindings pointing at the user if the alias changed
+	c.renameAlias(c.Users[index].Alias, user.Alias)
+
 	c.Users[index] = user
 	return nil
 }
 
-// DeleteUser deletes a user from the config or returns an error if the index is out of bounds
-func (c *Config) DeleteUser(index int) error {
-	if index < 0 || index > len(c.Users)-1 {
-		return ErrUserIndexOutOfBounds
+// DeleteUser deletes the user with the given ID or alias or returns an error if there is no such user. The order of
+// the remaining users is kept
+func (c *Config) DeleteUser(ref string) error {
+	index, err := c.userIndex(ref)
+	if err != nil {
+		return err
 	}
 
-	c.Users[index] = c.Users[len(c.Users)-1]
-	c.Users = c.Users[:len(c.Users)-1]
+	err = c.checkAliasUnused(c.Users[index].Alias)
+	if err != nil {
+		return err
+	}
 
+	c.Users = append(c.Users[:index], c.Users[index+1:]...)
 	return nil
 }
 
-// SelectUser selects an existing user from the config or returns an error if the index is out of bounds
-func (c *Config) SelectUser(index int) (*models.User, error) {
-	if index < 0 || index > len(c.Users)-1 {
-		return nil, ErrUserIndexOutOfBounds
+// SelectUser returns the user with the given ID or alias or an error if there is no such user
+func (c *Config) SelectUser(ref string) (*models.User, error) {
+	index, err := c.userIndex(ref)
+	if err != nil {
+		return nil, err
 	}
 
 	return &c.Users[index], nil
@@ -206,56 +285,511 @@ func (c *Config) SelectDefaultUser() (*models.User, error) {
 
 // SelectUserByAlias returns a user by alias or nil if there is no such user
 func (c *Config) SelectUserByAlias(alias string) (*models.User, error) {
-	for _, user := range c.Users {
+	for i, user := range c.Users {
 		if user.Alias == alias {
-			return &user, nil
+			return &c.Users[i], nil
 		}
 	}
 	return nil, ErrNoUserWithAlias
 }
 
+// FindUser returns the user with the given name and one of its emails or an error if there is no such user
+func (c *Config) FindUser(name, email string) (*models.User, error) {
+	for i, user := range c.Users {
+		if user.Name == name && user.HasEmail(email) {
+			return &c.Users[i], nil
+		}
+	}
+	return nil, ErrNoMatchingUser
+}
+
+// FindUserByEmail returns the first user with the given primary or further email or an error if there is no such user
+func (c *Config) FindUserByEmail(email string) (*models.User, error) {
+	for i, user := range c.Users {
+		if user.HasEmail(email) {
+			return &c.Users[i], nil
+		}
+	}
+	return nil, ErrNoMatchingUser
+}
+
 // UserList returns a list (slice) of formatted user data
 func (c *Config) UserList() []string {
-	var padding int = 0
-	var list []string
+	return FormatUserList(c.Users)
+}
+
+// Position returns the 1-based position of the user with the given ID in the user list or 0 if there is no such user
+func (c *Config) Position(id string) int {
+	for i, user := range c.Users {
+		if user.ID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// FilterUsers returns the users having all of the given tags, in list order
+func (c *Config) FilterUsers(tags []string) []models.User {
+	users := []models.User{}
 	for _, user := range c.Users {
-		if len(user.Alias) > padding {
-			padding = len(user.Alias) + 2
+		if user.HasTags(tags) {
+			users = append(users, user)
 		}
 	}
+	return users
+}
+
+// Reset deletes the users having all of the given tags, i.e. all users if no tags are given, together with the rules
+// and bindings pointing at them. The deleted rules and bindings are returned so that the caller can clean up the
+// include files of the bindings
+func (c *Config) Reset(tags []string) ([]models.Rule, []models.Binding) {
+	users := []models.User{}
+	removed := map[string]bool{}
 	for _, user := range c.Users {
-		list = append(list, user.Format(padding))
+		if !user.HasTags(tags) {
+			users = append(users, user)
+		} else if user.Alias != "" {
+			removed[user.Alias] = true
+		}
+	}
+	c.Users = users
+
+	var rules, deletedRules []models.Rule
+	for _, rule := range c.Rules {
+		if removed[rule.Alias] {
+			deletedRules = append(deletedRules, rule)
+		} else {
+			rules = append(rules, rule)
+		}
+	}
+	c.Rules = rules
+
+	var bindings, deletedBindings []models.Binding
+	for _, binding := range c.Bindings {
+		if removed[binding.Alias] {
+			deletedBindings = append(deletedBindings, binding)
+		} else {
+			bindings = append(bindings, binding)
+		}
+	}
+	c.Bindings = bindings
+
+	return deletedRules, deletedBindings
+}
+
+// GroupUsers returns the users ordered by their first tag, so that users sharing it are listed together. Groups are
+// ordered by the first appearance of the tag, users without tags come last
+func GroupUsers(users []models.User) []models.User {
+	groups := make(map[string]int)
+	group := func(user models.User) int {
+		if len(user.Tags) == 0 {
+			return len(users)
+		}
+		if _, ok := groups[user.Tags[0]]; !ok {
+			groups[user.Tags[0]] = len(groups)
+		}
+		return groups[user.Tags[0]]
+	}
+
+	grouped := append([]models.User{}, users...)
+	for _, user := range grouped {
+		group(user)
+	}
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return group(grouped[i]) < group(grouped[j])
+	})
+	return grouped
+}
+
+// FormatUserList returns a list (slice) of formatted user data. If any user has tags, the entries start with the first
+// tag of the user
+func FormatUserList(users []models.User) []string {
+	var padding int = 0
+	var tagPadding int = 0
+	var list []string
+	for _, user := range users {
+		if len(user.Label()) > padding {
+			padding = len(user.Label())
+		}
+		if len(user.Tags) > 0 && len(user.Tags[0]) > tagPadding {
+			tagPadding = len(user.Tags[0])
+		}
+	}
+	for _, user := range users {
+		if tagPadding == 0 {
+			list = append(list, user.Format(padding))
+			continue
+		}
+
+		var tag string
+		if len(user.Tags) > 0 {
+			tag = user.Tags[0]
+		}
+		list = append(list, fmt.Sprintf("%-*s  %s", tagPadding, tag, user.Format(padding)))
 	}
 	return list
 }
 
-// Reset resets the saved user profiles
-func (c *Config) Reset() {
-	c.Users = []models.User{}
+// AddRule adds a new rule to the config or returns an error if the rule is invalid
+func (c *Config) AddRule(rule *models.Rule) error {
+	err := rule.Validate()
+	if err != nil {
+		return fmt.Errorf("Invalid pattern %s: %w", rule.Pattern, err)
+	}
+
+	_, err = c.SelectUserByAlias(rule.Alias)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, rule.Alias)
+	}
+
+	for _, r := range c.Rules {
+		if r.Pattern == rule.Pattern {
+			return fmt.Errorf("A rule with pattern %s already exists: [%s]", r.Pattern, r.Alias)
+		}
+	}
+
+	c.Rules = append(c.Rules, *rule)
+	return nil
+}
+
+// DeleteRule deletes the rule with the given pattern or returns an error if there is no such rule
+func (c *Config) DeleteRule(pattern string) error {
+	for i, rule := range c.Rules {
+		if rule.Pattern == pattern {
+			c.Rules = append(c.Rules[:i], c.Rules[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNoRuleWithPattern
+}
+
+// MatchRule returns the most specific rule matching the remote URL or an error if no rule matches. A rule matching
+// more path segments of the remote is more specific, so "github.com/org" takes precedence over "github.com"
+// regardless of the order the rules were added in. Of equally specific rules the first one wins
+func (c *Config) MatchRule(remoteURL string) (*models.Rule, error) {
+	var match *models.Rule
+	matchDepth := -1
+	for i := range c.Rules {
+		if depth := c.Rules[i].MatchDepth(remoteURL); depth > matchDepth {
+			rule := c.Rules[i]
+			match, matchDepth = &rule, depth
+		}
+	}
+	if match == nil {
+		return nil, ErrNoMatchingRule
+	}
+	return match, nil
+}
+
+// RuleList returns a list (slice) of formatted rule data
+func (c *Config) RuleList() []string {
+	var padding int = 0
+	var list []string
+	for _, rule := range c.Rules {
+		if len(rule.Pattern) > padding {
+			padding = len(rule.Pattern)
+		}
+	}
+	for _, rule := range c.Rules {
+		list = append(list, rule.Format(padding))
+	}
+	return list
+}
+
+// AddBinding adds a new binding to the config or returns an error if the binding is invalid
+func (c *Config) AddBinding(binding *models.Binding) error {
+	_, err := c.SelectUserByAlias(binding.Alias)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, binding.Alias)
+	}
+
+	for _, b := range c.Bindings {
+		if b.Dir == binding.Dir {
+			return fmt.Errorf("Directory %s is already bound to [%s]", b.Dir, b.Alias)
+		}
+	}
+
+	err = c.checkIncludeFileUnused(binding.Alias, binding.Alias)
+	if err != nil {
+		return err
+	}
+
+	c.Bindings = append(c.Bindings, *binding)
+	return nil
+}
+
+// DeleteBinding deletes the binding of the directory and returns it or returns an error if there is no such binding
+func (c *Config) DeleteBinding(dir string) (*models.Binding, error) {
+	for i, binding := range c.Bindings {
+		if binding.Dir == dir {
+			c.Bindings = append(c.Bindings[:i], c.Bindings[i+1:]...)
+			return &binding, nil
+		}
+	}
+	return nil, ErrNoBindingForDir
+}
+
+// BindingsByAlias returns the bindings of the user with the given alias
+func (c *Config) BindingsByAlias(alias string) []models.Binding {
+	var bindings []models.Binding
+	for _, binding := range c.Bindings {
+		if alias != "" && binding.Alias == alias {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings
+}
+
+// ExpectedUser returns the user a repository is expected to use according to the rules and bindings, together with
+// a description of the rule or binding. Rules matching the remote URL take precedence over bindings of the directory
+// the repository is in. Of nested bindings the last one applies, as git applies the includeIf sections in the order
+// they were added. The home directory is used to expand bindings below "~/"
+func (c *Config) ExpectedUser(remoteURL, dir, home string) (*models.User, string, error) {
+	if remoteURL != "" {
+		if rule, err := c.MatchRule(remoteURL); err == nil {
+			user, err := c.SelectUserByAlias(rule.Alias)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %s", err, rule.Alias)
+			}
+			return user, "rule " + rule.Pattern, nil
+		}
+	}
+
+	for i := len(c.Bindings) - 1; i >= 0; i-- {
+		binding := c.Bindings[i]
+		if binding.Contains(dir, home) {
+			user, err := c.SelectUserByAlias(binding.Alias)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %s", err, binding.Alias)
+			}
+			return user, "binding " + binding.Dir, nil
+		}
+	}
+
+	return nil, "", ErrNoExpectedUser
+}
+
+// BindingList returns a list (slice) of formatted binding data
+func (c *Config) BindingList() []string {
+	var padding int = 0
+	var list []string
+	for _, binding := range c.Bindings {
+		if len(binding.Dir) > padding {
+			padding = len(binding.Dir)
+		}
+	}
+	for _, binding := range c.Bindings {
+		list = append(list, binding.Format(padding))
+	}
+	return list
 }
 
 // isValidUser returns if the provided user is valid
 func (c *Config) isValidUser(newUser *models.User, index int) error {
+	identity, alias := c.conflicts(newUser, index)
+
+	// Same name and email already exist
+	if identity >= 0 {
+		user := c.Users[identity]
+		return fmt.Errorf("User %s <%s> already exists", user.Name, user.Email)
+	}
+
+	// Alias already exists
+	if alias >= 0 {
+		return fmt.Errorf(
+			"A user with alias [%s] already exists: %s <%s>",
+			newUser.Alias,
+			newUser.Name,
+			newUser.Email,
+		)
+	}
+
+	// Alias is the ID of another user
+	if i := c.idConflict(newUser, index); i >= 0 {
+		user := c.Users[i]
+		return fmt.Errorf("The alias [%s] is the ID of the user %s <%s>", newUser.Alias, user.Name, user.Email)
+	}
+	return nil
+}
+
+// idConflict returns the index of the user other than the user at the given index whose ID is the alias of the new
+// user or -1. IDs take precedence over aliases when selecting users, so an alias equal to an ID could never be selected
+func (c *Config) idConflict(newUser *models.User, index int) int {
+	for i, user := range c.Users {
+		if i != index && newUser.Alias != "" && user.ID == newUser.Alias {
+			return i
+		}
+	}
+	return -1
+}
+
+// conflicts returns the indexes of the users with the same name and email and with the same alias as the provided
+// user, or -1 if there is no such user. The user at index is skipped
+func (c *Config) conflicts(newUser *models.User, index int) (int, int) {
+	identity, alias := -1, -1
 	for i, user := range c.Users {
 		// Looking at the same profile, skip
 		if i == index {
 			continue
 		}
 
-		// Same name and email already exist
-		if user.Name == newUser.Name && user.Email == newUser.Email {
-			return fmt.Errorf("User %s <%s> already exists", user.Name, user.Email)
+		if identity < 0 && user.Name == newUser.Name && user.Email == newUser.Email {
+			identity = i
 		}
 
-		// Alias already exists
-		if newUser.Alias != "" && user.Alias == newUser.Alias {
-			return fmt.Errorf(
-				"A user with alias [%s] already exists: %s <%s>",
-				newUser.Alias,
-				newUser.Name,
-				newUser.Email,
-			)
+		if alias < 0 && newUser.Alias != "" && user.Alias == newUser.Alias {
+			alias = i
+		}
+	}
+	return identity, alias
+}
+
+// EmailPolicyFor returns the email policy applying to the user, which is the global policy with the allowed domains
+// replaced by the user's allowed domains if it has any
+func (c *Config) EmailPolicyFor(user *models.User) models.EmailPolicy {
+	var policy models.EmailPolicy
+	if c.EmailPolicy != nil {
+		policy = *c.EmailPolicy
+	}
+	if len(user.AllowedDomains) > 0 {
+		policy.AllowedDomains = user.AllowedDomains
+	}
+	return policy
+}
+
+// userIndex returns the index of the user with the given ID, or with the given alias if no ID matches
+func (c *Config) userIndex(ref string) (int, error) {
+	for i, user := range c.Users {
+		if user.ID == ref {
+			return i, nil
+		}
+	}
+	for i, user := range c.Users {
+		if user.Alias != "" && user.Alias == ref {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w: %s", ErrNoSuchUser, ref)
+}
+
+// newUserID returns a random ID that is not used as ID or alias of another user yet
+func (c *Config) newUserID() (string, error) {
+	for {
+		id, err := randomID()
+		if err != nil {
+			return "", err
+		}
+		if _, err := c.userIndex(id); err != nil {
+			return id, nil
+		}
+	}
+}
+
+// nextOrder returns the sort key placing a new user at the end of the user list
+func (c *Config) nextOrder() int {
+	order := 0
+	for _, user := range c.Users {
+		if user.Order > order {
+			order = user.Order
+		}
+	}
+	return order + 1
+}
+
+// sortUsers sorts the users by their sort key
+func (c *Config) sortUsers() {
+	sort.SliceStable(c.Users, func(i, j int) bool {
+		return c.Users[i].Order < c.Users[j].Order
+	})
+}
+
+// randomID returns a random ID of 8 hex digits
+func randomID() (string, error) {
+	b := make([]byte, 4)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate a user ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validateUserFields validates the fields of a user that do not depend on other users, including the emails against
+// the email policy applying to the user
+func (c *Config) validateUserFields(user *models.User) error {
+	err := models.ValidateSigningFormat(user.SigningFormat)
+	if err != nil {
+		return err
+	}
+
+	err = user.ValidateTags()
+	if err != nil {
+		return err
+	}
+
+	err = user.ValidateEmails(c.EmailPolicyFor(user))
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, email := range user.AllEmails() {
+		if seen[strings.ToLower(email)] {
+			return fmt.Errorf("Email %s is listed more than once", email)
+		}
+		seen[strings.ToLower(email)] = true
+	}
+
+	return user.ValidateGitConfig()
+}
+
+// checkAliasUnused returns an error if a rule or binding points at the user with the alias
+func (c *Config) checkAliasUnused(alias string) error {
+	if alias == "" {
+		return nil
+	}
+
+	for _, rule := range c.Rules {
+		if rule.Alias == alias {
+			return fmt.Errorf("User [%s] is used by rule %s, delete the rule first", alias, rule.Pattern)
+		}
+	}
+	for _, binding := range c.Bindings {
+		if binding.Alias == alias {
+			return fmt.Errorf("User [%s] is bound to %s, unbind it first", alias, binding.Dir)
+		}
+	}
+	return nil
+}
+
+// checkIncludeFileUnused returns an error if a bound alias other than the alias itself or the ignored alias maps to
+// the same include file. File names are compared case-insensitively for case-insensitive file systems
+func (c *Config) checkIncludeFileUnused(alias, ignore string) error {
+	name := includeFileName(alias)
+	for _, binding := range c.Bindings {
+		if binding.Alias == alias || binding.Alias == ignore {
+			continue
+		}
+		if strings.EqualFold(includeFileName(binding.Alias), name) {
+			return fmt.Errorf("The include file of [%s] would collide with the one of the bound user [%s], use a different alias", alias, binding.Alias)
 		}
 	}
 	return nil
 }
+
+// renameAlias updates rules and bindings pointing at a user whose alias changed
+func (c *Config) renameAlias(oldAlias, newAlias string) {
+	if oldAlias == "" || oldAlias == newAlias {
+		return
+	}
+
+	for i := range c.Rules {
+		if c.Rules[i].Alias == oldAlias {
+			c.Rules[i].Alias = newAlias
+		}
+	}
+	for i := range c.Bindings {
+		if c.Bindings[i].Alias == oldAlias {
+			c.Bindings[i].Alias = newAlias
+		}
+	}
+}
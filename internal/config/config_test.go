@@ -0,0 +1,130 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+func TestMatchRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []models.Rule
+		remoteURL string
+		alias     string
+		wantErr   bool
+	}{
+		{
+			name:      "specific rule added after broad rule",
+			rules:     []models.Rule{{Pattern: "github.com", Alias: "oss"}, {Pattern: "github.com/corp", Alias: "work"}},
+			remoteURL: "git@github.com:corp/repo.git",
+			alias:     "work",
+		},
+		{
+			name:      "specific rule added before broad rule",
+			rules:     []models.Rule{{Pattern: "github.com/corp", Alias: "work"}, {Pattern: "github.com", Alias: "oss"}},
+			remoteURL: "git@github.com:corp/repo.git",
+			alias:     "work",
+		},
+		{
+			name:      "broad rule for other paths",
+			rules:     []models.Rule{{Pattern: "github.com", Alias: "oss"}, {Pattern: "github.com/corp", Alias: "work"}},
+			remoteURL: "git@github.com:john/repo.git",
+			alias:     "oss",
+		},
+		{
+			name:      "repository rule",
+			rules:     []models.Rule{{Pattern: "github.com/corp", Alias: "work"}, {Pattern: "github.com/corp/oss", Alias: "oss"}},
+			remoteURL: "https://github.com/corp/oss.git",
+			alias:     "oss",
+		},
+		{
+			name:      "first of equally specific rules",
+			rules:     []models.Rule{{Pattern: "github.com/*-corp", Alias: "work"}, {Pattern: "github.com/acme-corp", Alias: "oss"}},
+			remoteURL: "https://github.com/acme-corp/repo.git",
+			alias:     "work",
+		},
+		{
+			name:      "no matching rule",
+			rules:     []models.Rule{{Pattern: "github.com/corp", Alias: "work"}},
+			remoteURL: "https://gitlab.com/corp/repo.git",
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Config{Rules: test.rules}
+			rule, err := c.MatchRule(test.remoteURL)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("MatchRule() error = %v, want error %v", err, test.wantErr)
+			}
+			if err == nil && rule.Alias != test.alias {
+				t.Errorf("MatchRule() = %s -> [%s], want [%s]", rule.Pattern, rule.Alias, test.alias)
+			}
+		})
+	}
+}
+
+func TestExpectedUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		bindings  []models.Binding
+		remoteURL string
+		dir       string
+		alias     string
+		wantErr   bool
+	}{
+		{
+			name:     "nested binding added last",
+			bindings: []models.Binding{{Dir: "~/work/", Alias: "work"}, {Dir: "~/work/oss/", Alias: "oss"}},
+			dir:      "/home/john/work/oss/repo",
+			alias:    "oss",
+		},
+		{
+			name:     "outer binding of nested bindings",
+			bindings: []models.Binding{{Dir: "~/work/", Alias: "work"}, {Dir: "~/work/oss/", Alias: "oss"}},
+			dir:      "/home/john/work/repo",
+			alias:    "work",
+		},
+		{
+			name:     "outer binding added last",
+			bindings: []models.Binding{{Dir: "~/work/oss/", Alias: "oss"}, {Dir: "~/work/", Alias: "work"}},
+			dir:      "/home/john/work/oss/repo",
+			alias:    "work",
+		},
+		{
+			name:      "rule before binding",
+			bindings:  []models.Binding{{Dir: "~/work/", Alias: "work"}},
+			remoteURL: "git@github.com:john/repo.git",
+			dir:       "/home/john/work/repo",
+			alias:     "oss",
+		},
+		{
+			name:     "no binding",
+			bindings: []models.Binding{{Dir: "~/work/", Alias: "work"}},
+			dir:      "/home/john/src/repo",
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &Config{
+				Users: []models.User{
+					{ID: "0123abcd", Name: "John", Email: "john@corp.dev", Alias: "work"},
+					{ID: "4567cdef", Name: "John", Email: "john@johndoe.dev", Alias: "oss"},
+				},
+				Rules:    []models.Rule{{Pattern: "github.com/john", Alias: "oss"}},
+				Bindings: test.bindings,
+			}
+			user, _, err := c.ExpectedUser(test.remoteURL, test.dir, "/home/john")
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ExpectedUser() error = %v, want error %v", err, test.wantErr)
+			}
+			if err == nil && user.Alias != test.alias {
+				t.Errorf("ExpectedUser() = [%s], want [%s]", user.Alias, test.alias)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on the lock file, blocking until it is available
+func lockFile(path string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() error {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
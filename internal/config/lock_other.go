@@ -0,0 +1,37 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 10 * time.Second
+)
+
+// lockFile takes an exclusive lock by creating the lock file, which fails while another process holds it. It retries
+// until the lock is available or the timeout is reached
+func lockFile(path string) (func() error, error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			file.Close()
+			return func() error {
+				return os.Remove(path)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Config is locked by another gitsu process, remove %s if it is stale", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
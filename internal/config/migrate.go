@@ -0,0 +1,154 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// migration upgrades raw config data by one schema version
+type migration func(raw map[string]interface{}) error
+
+// migrations upgrade raw config data, the migration at index i upgrades schema version i to i+1. New migrations are
+// appended, which also increases the current schema version
+var migrations = []migration{
+	migrateV0ToV1,
+	migrateV1ToV2,
+	migrateV2ToV3,
+}
+
+// CurrentVersion returns the config schema version written by this version of gitsu
+func CurrentVersion() int {
+	return len(migrations)
+}
+
+// decode migrates raw config data to the current schema version and decodes it into a config
+func decode(raw map[string]interface{}) (*Config, error) {
+	storedVersion, err := migrate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(Config)
+	err = remarshal(raw, c)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Version = strconv.Itoa(CurrentVersion())
+	c.storedVersion = storedVersion
+	c.sortUsers()
+	return c, nil
+}
+
+// migrate upgrades raw config data to the current schema version in place and returns the schema version it was
+// stored with
+func migrate(raw map[string]interface{}) (int, error) {
+	version, err := parseVersion(raw["version"])
+	if err != nil {
+		return 0, err
+	}
+	storedVersion := version
+
+	if version > CurrentVersion() {
+		return 0, fmt.Errorf(
+			"%w (schema version %d, supported up to %d), please upgrade gitsu",
+			ErrConfigVersionTooNew,
+			version,
+			CurrentVersion(),
+		)
+	}
+
+	for ; version < CurrentVersion(); version++ {
+		err = migrations[version](raw)
+		if err != nil {
+			return 0, fmt.Errorf("failed to migrate config from schema version %d: %w", version, err)
+		}
+	}
+
+	// The version may have been written as a number by hand
+	raw["version"] = strconv.Itoa(version)
+	return storedVersion, nil
+}
+
+// remarshal decodes migrated raw config data into v
+func remarshal(raw map[string]interface{}, v interface{}) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// parseVersion returns the schema version of raw config data. Config files written before schema versions were
+// introduced have an empty version
+func parseVersion(v interface{}) (int, error) {
+	switch version := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return int(version), nil
+	case string:
+		if version == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(version)
+		if err != nil {
+			return 0, fmt.Errorf("invalid config schema version %q", version)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid config schema version %v", v)
+	}
+}
+
+// migrateV0ToV1 upgrades config files without schema version. These may contain a null user list
+func migrateV0ToV1(raw map[string]interface{}) error {
+	if raw["users"] == nil {
+		raw["users"] = []interface{}{}
+	}
+	return nil
+}
+
+// migrateV1ToV2 renames the GPG key ID of users to signing key, which may also hold SSH or X.509 keys
+func migrateV1ToV2(raw map[string]interface{}) error {
+	users, _ := raw["users"].([]interface{})
+	for _, u := range users {
+		user, ok := u.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid user %v", u)
+		}
+
+		if keyID, ok := user["gpg_key_id"]; ok {
+			user["signing_key"] = keyID
+			delete(user, "gpg_key_id")
+		}
+	}
+	return nil
+}
+
+// migrateV2ToV3 gives every user an ID and a sort key keeping the current order. The IDs are derived from the user
+// data, so they do not change when the config is read again before it is written in the new schema version
+func migrateV2ToV3(raw map[string]interface{}) error {
+	users, _ := raw["users"].([]interface{})
+	ids := make(map[string]bool)
+	for i, u := range users {
+		user, ok := u.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid user %v", u)
+		}
+
+		var id string
+		for n := 0; id == "" || ids[id]; n++ {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%v\x00%v\x00%d", i, user["name"], user["email"], n)))
+			id = hex.EncodeToString(sum[:4])
+		}
+		ids[id] = true
+
+		user["id"] = id
+		user["order"] = i + 1
+	}
+	return nil
+}
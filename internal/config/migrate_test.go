@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		storedVersion int
+		signingKeys   []string
+		err           error
+	}{
+		{
+			name:          "no version and null users",
+			data:          `{"users": null}`,
+			storedVersion: 0,
+			signingKeys:   []string{},
+		},
+		{
+			name:          "gpg key ID of version 1",
+			data:          `{"version": "1", "users": [{"name": "John", "email": "john@corp.dev", "gpg_key_id": "0123ABCD"}]}`,
+			storedVersion: 1,
+			signingKeys:   []string{"0123ABCD"},
+		},
+		{
+			name:          "users without IDs of version 2",
+			data:          `{"version": "2", "users": [{"name": "A", "email": "a@corp.dev"}, {"name": "B", "email": "b@corp.dev"}]}`,
+			storedVersion: 2,
+			signingKeys:   []string{"", ""},
+		},
+		{
+			name:          "numeric version",
+			data:          `{"version": 3, "users": []}`,
+			storedVersion: 3,
+			signingKeys:   []string{},
+		},
+		{
+			name: "version too new",
+			data: `{"version": "99", "users": []}`,
+			err:  ErrConfigVersionTooNew,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			raw := make(map[string]interface{})
+			err := json.Unmarshal([]byte(test.data), &raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := decode(raw)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("decode() error = %v, want %v", err, test.err)
+			}
+			if err != nil {
+				return
+			}
+
+			if c.StoredVersion() != test.storedVersion {
+				t.Errorf("StoredVersion() = %d, want %d", c.StoredVersion(), test.storedVersion)
+			}
+			if c.Version != strconv.Itoa(CurrentVersion()) {
+				t.Errorf("Version = %s, want %d", c.Version, CurrentVersion())
+			}
+			if len(c.Users) != len(test.signingKeys) {
+				t.Fatalf("got %d users, want %d", len(c.Users), len(test.signingKeys))
+			}
+
+			ids := make(map[string]bool)
+			for i, user := range c.Users {
+				if user.SigningKey != test.signingKeys[i] {
+					t.Errorf("user %d: SigningKey = %q, want %q", i, user.SigningKey, test.signingKeys[i])
+				}
+				if user.ID == "" || ids[user.ID] {
+					t.Errorf("user %d: ID %q is empty or not unique", i, user.ID)
+				}
+				ids[user.ID] = true
+				if user.Order != i+1 {
+					t.Errorf("user %d: Order = %d, want %d", i, user.Order, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateV2ToV3IsDeterministic(t *testing.T) {
+	data := `{"version": "2", "users": [{"name": "A", "email": "a@corp.dev"}, {"name": "A", "email": "a@corp.dev"}]}`
+
+	var ids [2][]string
+	for i := range ids {
+		raw := make(map[string]interface{})
+		err := json.Unmarshal([]byte(data), &raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := decode(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, user := range c.Users {
+			ids[i] = append(ids[i], user.ID)
+		}
+	}
+
+	if ids[0][0] != ids[1][0] || ids[0][1] != ids[1][1] {
+		t.Errorf("IDs differ between reads: %v and %v", ids[0], ids[1])
+	}
+	if ids[0][0] == ids[0][1] {
+		t.Errorf("users with the same data got the same ID %s", ids[0][0])
+	}
+}
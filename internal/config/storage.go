@@ -0,0 +1,65 @@
+package config
+
+import (
+	"path/filepath"
+)
+
+// Storage describes where the config is kept. Load returns ErrConfigFileDoesNotExist if no config has been saved
+// yet. Lock takes an exclusive lock and returns a function releasing it
+type Storage interface {
+	Exists() (bool, error)
+	Load() (*Config, error)
+	Save(c *Config) error
+	Lock() (func() error, error)
+}
+
+var (
+	// storage is the storage used by Read, Write and Lock. It defaults to the JSON file at Path
+	storage Storage
+
+	// customPath is the config file path selected via SetPath
+	customPath string
+)
+
+// SetPath selects the config file at path instead of the default one. The file extension selects the format
+// (.json, .yaml / .yml or .toml)
+func SetPath(path string) error {
+	format, err := Format("", path)
+	if err != nil {
+		return err
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	customPath = abs
+	storage = NewFileStorage(abs, format)
+	return nil
+}
+
+// CustomPath returns the config file path selected via SetPath or an empty string if the default one is used
+func CustomPath() string {
+	return customPath
+}
+
+// SetStorage selects the storage used by Read, Write and Lock
+func SetStorage(s Storage) {
+	storage = s
+}
+
+// currentStorage returns the selected storage or the default JSON file storage
+func currentStorage() (Storage, error) {
+	if storage != nil {
+		return storage, nil
+	}
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	storage = NewFileStorage(path, "json")
+	return storage, nil
+}
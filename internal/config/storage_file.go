@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/matsuyoshi30/gitsu/internal/constants"
+	"github.com/matsuyoshi30/gitsu/internal/utils"
+)
+
+// FileStorage keeps the config in a JSON, YAML or TOML file
+type FileStorage struct {
+	path   string
+	format string
+}
+
+// NewFileStorage returns a storage for the config file at path in the given format
+func NewFileStorage(path, format string) *FileStorage {
+	return &FileStorage{
+		path:   path,
+		format: format,
+	}
+}
+
+// Exists returns if the config file exists
+func (s *FileStorage) Exists() (bool, error) {
+	return utils.FileExists(s.path), nil
+}
+
+// Load reads the config file. Config files of older schema versions are migrated, files of newer versions are
+// rejected
+func (s *FileStorage) Load() (*Config, error) {
+	if !utils.FileExists(s.path) {
+		return nil, ErrConfigFileDoesNotExist
+	}
+
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := unmarshalRaw(b, s.format)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(raw)
+}
+
+// Save writes the config file. The data is written to a temporary file first which then replaces the config file,
+// the previous version is kept as a backup
+func (s *FileStorage) Save(c *Config) error {
+	// Check if the config directory exists. If not, create it
+	configDir := filepath.Dir(s.path)
+	if !utils.DirExists(configDir) {
+		err := os.MkdirAll(configDir, 0744)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := marshal(c, s.format)
+	if err != nil {
+		return err
+	}
+
+	if utils.FileExists(s.path) {
+		err = s.backup()
+		if err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	return utils.WriteFileAtomic(s.path, data, 0644)
+}
+
+// backup copies the config file to the first of constants.BackupCount numbered backups, shifting the existing ones
+// and dropping the oldest
+func (s *FileStorage) backup() error {
+	previous, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(BackupPath(s.path, constants.BackupCount))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for n := constants.BackupCount - 1; n >= 1; n-- {
+		err = os.Rename(BackupPath(s.path, n), BackupPath(s.path, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return utils.WriteFileAtomic(BackupPath(s.path, 1), previous, 0644)
+}
+
+// BackupPath returns the path of the nth backup of the config file at path, the first being the most recent one
+func BackupPath(path string, n int) string {
+	return fmt.Sprintf("%s%s.%d", path, constants.BackupFileExt, n)
+}
+
+// Lock takes an exclusive advisory lock on a lock file next to the config file
+func (s *FileStorage) Lock() (func() error, error) {
+	err := os.MkdirAll(filepath.Dir(s.path), 0744)
+	if err != nil {
+		return nil, err
+	}
+
+	return lockFile(s.path + constants.LockFileExt)
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+func TestFileStorageBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	s := NewFileStorage(path, "json")
+
+	for i := 0; i < 7; i++ {
+		c := &Config{Users: []models.User{{Name: strconv.Itoa(i), Email: "john@corp.dev"}}}
+		err := s.Save(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for n := 1; n <= 5; n++ {
+		backup, err := NewFileStorage(BackupPath(path, n), "json").Load()
+		if err != nil {
+			t.Fatalf("backup %d: %v", n, err)
+		}
+		if want := strconv.Itoa(6 - n); backup.Users[0].Name != want {
+			t.Errorf("backup %d has name %s, want %s", n, backup.Users[0].Name, want)
+		}
+	}
+	if exists, _ := NewFileStorage(BackupPath(path, 6), "json").Exists(); exists {
+		t.Errorf("backup 6 exists, want at most 5 backups")
+	}
+}
+
+func TestReadOrNewDoesNotWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	SetStorage(NewFileStorage(path, "json"))
+	defer SetStorage(nil)
+
+	c, err := ReadOrNew()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := Exists(); exists {
+		t.Fatalf("ReadOrNew() created the config file")
+	}
+
+	c.Users = append(c.Users, models.User{ID: "0123abcd", Name: "John", Email: "john@corp.dev"})
+	err = Write(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := NewFileStorage(BackupPath(path, 1), "json").Exists(); exists {
+		t.Errorf("the first write left a backup")
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// MemoryStorage keeps the config in memory, e.g. for tests. Loaded configs are copies, so changes only take effect
+// when they are saved. The data has its own mutex, as Load and Save are called while the lock is held
+type MemoryStorage struct {
+	data      []byte
+	dataMutex sync.Mutex
+	mutex     sync.Mutex
+}
+
+// NewMemoryStorage returns an empty memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+// Exists returns if a config has been saved
+func (s *MemoryStorage) Exists() (bool, error) {
+	s.dataMutex.Lock()
+	defer s.dataMutex.Unlock()
+
+	return s.data != nil, nil
+}
+
+// Load returns a copy of the saved config
+func (s *MemoryStorage) Load() (*Config, error) {
+	s.dataMutex.Lock()
+	data := s.data
+	s.dataMutex.Unlock()
+
+	if data == nil {
+		return nil, ErrConfigFileDoesNotExist
+	}
+
+	raw := make(map[string]interface{})
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(raw)
+}
+
+// Save saves a copy of the config
+func (s *MemoryStorage) Save(c *Config) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	s.dataMutex.Lock()
+	defer s.dataMutex.Unlock()
+
+	s.data = data
+	return nil
+}
+
+// Lock takes an exclusive lock within the process
+func (s *MemoryStorage) Lock() (func() error, error) {
+	s.mutex.Lock()
+	return func() error {
+		s.mutex.Unlock()
+		return nil
+	}, nil
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+// testConfig returns a config using all fields of the schema
+func testConfig() *Config {
+	added := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	return &Config{
+		Version: strconv.Itoa(CurrentVersion()),
+		Users: []models.User{
+			{
+				ID:                 "0123abcd",
+				Order:              1,
+				Name:               "John Doe",
+				Email:              "john@corp.dev",
+				Emails:             []string{"1+john@users.noreply.github.com"},
+				Alias:              "work",
+				Tags:               []string{"client-a"},
+				SigningKey:         "~/.ssh/id_ed25519.pub",
+				SigningFormat:      "ssh",
+				SignCommits:        true,
+				SignTags:           true,
+				AllowedSignersFile: "~/.ssh/allowed_signers",
+				SSHKey:             "~/.ssh/id_ed25519",
+				GitConfig:          map[string]string{"commit.template": "~/.gitmessage"},
+				AllowedDomains:     []string{"corp.dev"},
+				AddedAt:            added,
+				ModifiedAt:         added.Add(time.Hour),
+			},
+			{
+				ID:      "4567cdef",
+				Order:   2,
+				Name:    "John Doe",
+				Email:   "john@johndoe.dev",
+				AddedAt: added,
+			},
+		},
+		Rules:       []models.Rule{{Pattern: "github.com/corp", Alias: "work"}},
+		Bindings:    []models.Binding{{Dir: "~/work/", Alias: "work"}},
+		EmailPolicy: &models.EmailPolicy{AllowedDomains: []string{"corp.dev", "johndoe.dev"}},
+	}
+}
+
+// assertConfigEqual compares configs, timestamps are compared by the instant they describe
+func assertConfigEqual(t *testing.T, got, want *Config) {
+	t.Helper()
+
+	if len(got.Users) != len(want.Users) {
+		t.Fatalf("got %d users, want %d", len(got.Users), len(want.Users))
+	}
+
+	got, want = copyConfig(got), copyConfig(want)
+	for i := range got.Users {
+		if !got.Users[i].AddedAt.Equal(want.Users[i].AddedAt) || !got.Users[i].ModifiedAt.Equal(want.Users[i].ModifiedAt) {
+			t.Errorf("user %d: timestamps %v, %v, want %v, %v", i, got.Users[i].AddedAt, got.Users[i].ModifiedAt, want.Users[i].AddedAt, want.Users[i].ModifiedAt)
+		}
+		got.Users[i].AddedAt, got.Users[i].ModifiedAt = time.Time{}, time.Time{}
+		want.Users[i].AddedAt, want.Users[i].ModifiedAt = time.Time{}, time.Time{}
+	}
+
+	got.storedVersion, want.storedVersion = 0, 0
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// copyConfig returns a copy of the config with its own user list
+func copyConfig(c *Config) *Config {
+	copied := *c
+	copied.Users = append([]models.User(nil), c.Users...)
+	return &copied
+}
+
+func TestFileStorageRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			s := NewFileStorage(filepath.Join(t.TempDir(), "config."+format), format)
+			want := testConfig()
+
+			err := s.Save(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertConfigEqual(t, got, want)
+
+			if got.StoredVersion() != CurrentVersion() {
+				t.Errorf("StoredVersion() = %d, want %d", got.StoredVersion(), CurrentVersion())
+			}
+		})
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage()
+	SetStorage(s)
+	defer SetStorage(nil)
+
+	exists, err := Exists()
+	if err != nil || exists {
+		t.Fatalf("Exists() = %v, %v before saving, want false", exists, err)
+	}
+	if _, err = Read(); err != ErrConfigFileDoesNotExist {
+		t.Fatalf("Read() error = %v, want %v", err, ErrConfigFileDoesNotExist)
+	}
+
+	unlock, err := Lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := testConfig()
+	err = Write(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertConfigEqual(t, got, want)
+
+	// Loaded configs are copies
+	got.Users[0].Name = "Changed"
+	again, err := Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Users[0].Name != want.Users[0].Name {
+		t.Errorf("changing a loaded config changed the stored one")
+	}
+}
@@ -3,5 +3,13 @@ package constants
 const (
 	ConfigDir      string = "gitsu-go"
 	ConfigFileName string = "config.json"
+	LockFileExt    string = ".lock"
+	BackupFileExt  string = ".bak"
+	BackupCount    int    = 5
+	IncludeDir     string = "includes"
+	IncludeFileExt string = ".gitconfig"
 	JsonIndent     string = "  "
+	ConfigEnvVar   string = "GITSU_CONFIG"
+	XdgConfigHome  string = "XDG_CONFIG_HOME"
+	PageSizeEnvVar string = "GITSU_PAGE_SIZE"
 )
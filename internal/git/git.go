@@ -3,7 +3,11 @@ package git
 import (
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/matsuyoshi30/gitsu/internal/models"
@@ -13,23 +17,202 @@ var (
 	ErrNotInsideWorktree = errors.New("not inside git worktree")
 )
 
-// SetConfig sets the user config via the 'git config' command with scope --global or --local
+// extraKeyOption is the git config option recording the keys of the extra git config of the selected user
+const extraKeyOption = "gitsu.extraKey"
+
+// SetConfig sets the user config via the 'git config' command with scope --global or --local. If any option can not
+// be set, all options changed so far are restored to their previous values
 func SetConfig(user *models.User, scope models.Scope) error {
-	err := gitConfigCommand("user.name", user.Name, scope)
+	location := []string{scope.Arg()}
+	s, err := takeSnapshot(user, location)
+	if err != nil {
+		return err
+	}
+
+	err = setUserConfig(user, location...)
+	if err != nil {
+		return s.rollback(err)
+	}
+	return nil
+}
+
+// GetConfig returns the value of a git config option in the given scope or an empty string if it is not set
+func GetConfig(option string, scope models.Scope) (string, error) {
+	return gitConfigGetCommand([]string{scope.Arg()}, option)
+}
+
+// GetPathConfig returns the value of a git config option holding a path in the given scope or an empty string if it
+// is not set. A leading "~/" is expanded by git
+func GetPathConfig(option string, scope models.Scope) (string, error) {
+	return gitConfigGetCommand([]string{scope.Arg(), "--type=path"}, option)
+}
+
+// GetEffectiveConfig returns the value of a git config option as git uses it, i.e. considering all config files and
+// includes, or an empty string if it is not set
+func GetEffectiveConfig(option string) (string, error) {
+	return gitConfigGetCommand(nil, option)
+}
+
+// ConfigOrigin describes where git takes the effective value of a config option from. Scope is the scope of the
+// config file or of the file including it, e.g. "local", "global" or "command". Origin is the file the value is set in
+// or a description like "command line:"
+type ConfigOrigin struct {
+	Value  string
+	Scope  string
+	Origin string
+}
+
+// GetConfigOrigin returns the effective value of a git config option together with its scope and origin via 'git
+// config --show-scope --show-origin', or nil if the option is not set. git versions before 2.26 do not know
+// --show-scope, so only the origin is returned for them
+func GetConfigOrigin(option string) (*ConfigOrigin, error) {
+	version, err := GetVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	showScope := version.AtLeast(showScopeVersion.Major, showScopeVersion.Minor)
+	args := []string{"config", "--show-origin", "--null", "--get", option}
+	if showScope {
+		args = append([]string{"config", "--show-scope"}, args[1:]...)
+	}
+
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		exit, ok := err.(*exec.ExitError)
+		if ok && exit.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s option via git: %w", option, err)
+	}
+	return parseConfigOrigin(string(out), showScope)
+}
+
+// parseConfigOrigin parses the NUL separated output of 'git config [--show-scope] --show-origin --null --get'
+func parseConfigOrigin(out string, showScope bool) (*ConfigOrigin, error) {
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	if showScope {
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected git config output %q", out)
+		}
+		return &ConfigOrigin{Scope: fields[0], Origin: formatOrigin(fields[1]), Value: fields[2]}, nil
+	}
+
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("unexpected git config output %q", out)
+	}
+	return &ConfigOrigin{Origin: formatOrigin(fields[0]), Value: fields[1]}, nil
+}
+
+// formatOrigin returns the path of a "file:<path>" origin and strips the trailing colon of origins without a path like
+// "command line:"
+func formatOrigin(origin string) string {
+	if strings.HasPrefix(origin, "file:") {
+		return strings.TrimPrefix(origin, "file:")
+	}
+	return strings.TrimSuffix(origin, ":")
+}
+
+// ReadUser returns the user defined by the user.name, user.email and user.signingkey options of the git config file
+// at path, or of the global git config if path is empty. Options that are not set are empty
+func ReadUser(path string) (*models.User, error) {
+	location := []string{"--global"}
+	if path != "" {
+		location = []string{"--file", path}
+	}
+
+	var values []string
+	for _, option := range []string{"user.name", "user.email", "user.signingkey"} {
+		value, err := gitConfigGetCommand(location, option)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return models.NewUser(values[0], values[1], "", values[2]), nil
+}
+
+// IncludePaths returns the paths of all files included by the global git config via include.path and
+// includeIf.<condition>.path options. A leading "~/" is expanded to home and relative paths are resolved against the
+// directory of the file containing the option, like git does
+func IncludePaths(home string) ([]string, error) {
+	out, err := exec.Command("git", "config", "--global", "--show-origin", "--null", "--get-regexp", `^include(if\..*)?\.path$`).Output()
+	if err != nil {
+		exit, ok := err.(*exec.ExitError)
+		if ok && exit.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get include options via git: %w", err)
+	}
+	return parseIncludePaths(string(out), home), nil
+}
+
+// parseIncludePaths parses the output of 'git config --show-origin --null --get-regexp'. Every entry consists of the
+// origin terminated by NUL, followed by key and value separated by a newline and terminated by NUL
+func parseIncludePaths(out, home string) []string {
+	var paths []string
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		j := strings.Index(fields[i+1], "\n")
+		if j < 0 {
+			continue
+		}
+
+		path := fields[i+1][j+1:]
+		if strings.HasPrefix(path, "~/") {
+			path = filepath.Join(home, path[2:])
+		} else if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(strings.TrimPrefix(fields[i], "file:")), path)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// WriteIncludeFile writes the user config to a standalone git config file that can be included via an
+// include.path or includeIf.<condition>.path option. The file is written next to path first and replaces it once
+// all options are set, so a failure leaves the previous version intact
+func WriteIncludeFile(user *models.User, path string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0744)
 	if err != nil {
-		return fmt.Errorf("failed to set user.name option via git: %w", err)
+		return err
 	}
 
-	err = gitConfigCommand("user.email", user.Email, scope)
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, nil, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to set user.email option via git: %w", err)
+		return err
+	}
+	defer os.Remove(tmp)
+
+	err = setUserConfig(user, "--file", tmp)
+	if err != nil {
+		return err
 	}
 
-	err = gitGPGKeyIDCommand(user.GpgKeyID, scope)
+	return os.Rename(tmp, path)
+}
+
+// AddInclude adds an 'includeIf.<condition>.path' option pointing at path to the global git config
+func AddInclude(condition, path string) error {
+	out, err := exec.Command("git", "config", "--global", "--add", includeOption(condition), path).Output()
 	if err != nil {
-		return fmt.Errorf("failed to set / unset user.signingkey option via git: %w", err)
+		return fmt.Errorf("failed to add includeIf option via git: %s: %w", out, err)
 	}
+	return nil
+}
 
+// RemoveInclude removes the 'includeIf.<condition>.path' option pointing at path from the global git config
+func RemoveInclude(condition, path string) error {
+	valuePattern := "^" + regexp.QuoteMeta(path) + "$"
+	out, err := exec.Command("git", "config", "--global", "--unset-all", includeOption(condition), valuePattern).Output()
+	if err != nil {
+		exit, ok := err.(*exec.ExitError)
+		if !ok || exit.ExitCode() != 5 {
+			return fmt.Errorf("failed to remove includeIf option via git: %s: %w", out, err)
+		}
+	}
 	return nil
 }
 
@@ -52,26 +235,197 @@ func IsInsideWorktree(scope models.Scope) error {
 	return ErrNotInsideWorktree
 }
 
-// gitConfigCommand executes a 'git config --global/--local <option> <value>' command
-func gitConfigCommand(option, value string, scope models.Scope) error {
-	out, err := exec.Command("git", "config", scope.Arg(), option, value).Output()
+// RemoteURL returns the URL of the remote with the given name via 'git remote get-url <name>'
+func RemoteURL(name string) (string, error) {
+	out, err := exec.Command("git", "remote", "get-url", name).Output()
 	if err != nil {
-		return fmt.Errorf("%s: %w", out, err)
+		return "", fmt.Errorf("failed to get URL of remote %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// configOption describes a git config option of a user. An empty value means the option is unset
+type configOption struct {
+	name  string
+	value string
+}
+
+// userOptions returns the git config options describing the user
+func userOptions(user *models.User) []configOption {
+	return []configOption{
+		{"user.name", user.Name},
+		{"user.email", user.Email},
+		{"user.signingkey", user.SigningKey},
+		{"gpg.format", user.SigningFormat},
+		{"gpg.ssh.allowedSignersFile", user.AllowedSignersFile},
+		{"commit.gpgsign", boolValue(user.SignCommits)},
+		{"tag.gpgsign", boolValue(user.SignTags)},
+		{"core.sshCommand", user.SSHCommand()},
+	}
+}
+
+// SetOption sets a single git config option with scope --global or --local
+func SetOption(option, value string, scope models.Scope) error {
+	err := gitConfigCommand([]string{scope.Arg()}, option, value)
+	if err != nil {
+		return fmt.Errorf("failed to set %s option via git: %w", option, err)
 	}
 	return nil
 }
 
-// gitGPGKeyIDCommand executes a 'git config --global/--local (--unset) user.signingkey <key ID>' command
-func gitGPGKeyIDCommand(gpgKeyID string, scope models.Scope) error {
-	var cmd *exec.Cmd
-	if gpgKeyID == "" {
-		cmd = exec.Command("git", "config", scope.Arg(), "--unset", "user.signingkey")
-	} else {
-		cmd = exec.Command("git", "config", scope.Arg(), "user.signingkey", gpgKeyID)
+// UnsetOption removes all values of a git config option with scope --global or --local. An option that is not set is
+// not an error
+func UnsetOption(option string, scope models.Scope) error {
+	err := gitConfigUnsetCommand([]string{scope.Arg()}, option)
+	if err != nil {
+		return fmt.Errorf("failed to unset %s option via git: %w", option, err)
+	}
+	return nil
+}
+
+// TopLevel returns the absolute path of the top level directory of the current worktree
+func TopLevel() (string, error) {
+	return revParse("--show-toplevel")
+}
+
+// HooksDir returns the absolute path of the hooks directory of the current repository, which respects core.hooksPath
+func HooksDir() (string, error) {
+	dir, err := revParse("--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(dir)
+}
+
+// revParse executes a 'git rev-parse <args>' command and returns its output
+func revParse(args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"rev-parse"}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run git rev-parse %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// setUserConfig sets the user config in the config file selected by the location arguments. Options the user does
+// not define are unset, so that no values of a previously selected user remain
+func setUserConfig(user *models.User, location ...string) error {
+	for _, option := range userOptions(user) {
+		var err error
+		if option.value == "" {
+			err = gitConfigUnsetCommand(location, option.name)
+		} else {
+			err = gitConfigCommand(location, option.name, option.value)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set / unset %s option via git: %w", option.name, err)
+		}
+	}
+
+	return setExtraConfig(user, location)
+}
+
+// setExtraConfig sets the user's extra git config options. The keys are recorded in the gitsu.extraKey option, so
+// that the options of the previously selected user can be unset if the new user does not define them
+func setExtraConfig(user *models.User, location []string) error {
+	previousKeys, err := gitConfigGetAllCommand(location, extraKeyOption)
+	if err != nil {
+		return err
 	}
 
-	out, err := cmd.Output()
+	for _, key := range previousKeys {
+		if _, ok := user.GitConfig[key]; ok {
+			continue
+		}
+
+		err = gitConfigUnsetCommand(location, key)
+		if err != nil {
+			return fmt.Errorf("failed to unset %s option via git: %w", key, err)
+		}
+	}
+
+	err = gitConfigUnsetCommand(location, extraKeyOption)
+	if err != nil {
+		return fmt.Errorf("failed to unset %s option via git: %w", extraKeyOption, err)
+	}
+
+	keys := make([]string, 0, len(user.GitConfig))
+	for key := range user.GitConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		err = gitConfigCommand(location, "--replace-all", key, user.GitConfig[key])
+		if err != nil {
+			return fmt.Errorf("failed to set %s option via git: %w", key, err)
+		}
+
+		err = gitConfigCommand(location, "--add", extraKeyOption, key)
+		if err != nil {
+			return fmt.Errorf("failed to set %s option via git: %w", extraKeyOption, err)
+		}
+	}
+
+	return nil
+}
+
+// boolValue returns the git config value of a boolean option, false options are unset
+func boolValue(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+// includeOption returns the name of the includeIf path option for the condition
+func includeOption(condition string) string {
+	return "includeIf." + condition + ".path"
+}
+
+// gitConfigCommand executes a 'git config <location> <args...>' command, e.g. with option and value
+func gitConfigCommand(location []string, arg ...string) error {
+	args := append(append([]string{"config"}, location...), arg...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// gitConfigGetCommand executes a 'git config <location> --get <option>' command. An unset option is not an error
+func gitConfigGetCommand(location []string, option string) (string, error) {
+	args := append(append([]string{"config"}, location...), "--get", option)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		exit, ok := err.(*exec.ExitError)
+		if ok && exit.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get %s option via git: %w", option, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// gitConfigGetAllCommand executes a 'git config <location> --get-all <option>' command and returns all values. An
+// unset option is not an error
+func gitConfigGetAllCommand(location []string, option string) ([]string, error) {
+	args := append(append([]string{"config"}, location...), "--get-all", option)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		exit, ok := err.(*exec.ExitError)
+		if ok && exit.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s option via git: %w", option, err)
+	}
+	return strings.Split(strings.TrimRight(string(out), "\r\n"), "\n"), nil
+}
 
+// gitConfigUnsetCommand executes a 'git config <location> --unset-all <option>' command. An option that is not set
+// is not an error
+func gitConfigUnsetCommand(location []string, option string) error {
+	args := append(append([]string{"config"}, location...), "--unset-all", option)
+	out, err := exec.Command("git", args...).Output()
 	if err != nil {
 		exit, ok := err.(*exec.ExitError)
 		if !ok || exit.ExitCode() != 5 {
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConfigOrigin(t *testing.T) {
+	tests := []struct {
+		name      string
+		out       string
+		showScope bool
+		want      *ConfigOrigin
+		wantErr   bool
+	}{
+		{
+			name:      "global file",
+			out:       "global\x00file:/home/john/.gitconfig\x00John Doe\x00",
+			showScope: true,
+			want:      &ConfigOrigin{Value: "John Doe", Scope: "global", Origin: "/home/john/.gitconfig"},
+		},
+		{
+			name:      "command line",
+			out:       "command\x00command line:\x00john@corp.dev\x00",
+			showScope: true,
+			want:      &ConfigOrigin{Value: "john@corp.dev", Scope: "command", Origin: "command line"},
+		},
+		{
+			name:      "value with tab",
+			out:       "local\x00file:.git/config\x00a\tb\x00",
+			showScope: true,
+			want:      &ConfigOrigin{Value: "a\tb", Scope: "local", Origin: ".git/config"},
+		},
+		{
+			name: "without scope",
+			out:  "file:/home/john/.gitconfig\x00John Doe\x00",
+			want: &ConfigOrigin{Value: "John Doe", Origin: "/home/john/.gitconfig"},
+		},
+		{
+			name:      "missing value",
+			out:       "global\x00file:/home/john/.gitconfig\x00",
+			showScope: true,
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseConfigOrigin(test.out, test.showScope)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseConfigOrigin() error = %v, want error %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseConfigOrigin() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseIncludePaths(t *testing.T) {
+	out := "file:/home/john/.gitconfig\x00include.path\ndotfiles/git/user\x00" +
+		"file:/home/john/.gitconfig\x00includeif.gitdir:~/work/.path\n~/.config/git/work\x00" +
+		"file:/home/john/.config/git/config\x00include.path\nlocal\x00" +
+		"file:/home/john/.gitconfig\x00include.path\n/etc/gitconfig.d/user\x00"
+	want := []string{
+		"/home/john/dotfiles/git/user",
+		"/home/john/.config/git/work",
+		"/home/john/.config/git/local",
+		"/etc/gitconfig.d/user",
+	}
+
+	got := parseIncludePaths(out, "/home/john")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseIncludePaths() = %v, want %v", got, want)
+	}
+}
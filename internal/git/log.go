@@ -0,0 +1,41 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Commit describes the identities recorded in a commit
+type Commit struct {
+	Hash           string
+	AuthorName     string
+	AuthorEmail    string
+	CommitterName  string
+	CommitterEmail string
+}
+
+// Log returns the commits of the revision range via 'git log <range>'
+func Log(revRange string) ([]Commit, error) {
+	out, err := exec.Command("git", "log", "--format=%H%x00%an%x00%ae%x00%cn%x00%ce", revRange, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log of %s via git: %w", revRange, err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+
+		commits = append(commits, Commit{
+			Hash:           fields[0],
+			AuthorName:     fields[1],
+			AuthorEmail:    fields[2],
+			CommitterName:  fields[3],
+			CommitterEmail: fields[4],
+		})
+	}
+	return commits, nil
+}
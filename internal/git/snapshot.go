@@ -0,0 +1,110 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matsuyoshi30/gitsu/internal/models"
+)
+
+// snapshot holds the values of git config options before a user is set, so that they can be restored if setting the
+// user fails half way
+type snapshot struct {
+	location []string
+	keys     []string
+	values   map[string][]string
+}
+
+// takeSnapshot records the current values of all options that setting the user may change
+func takeSnapshot(user *models.User, location []string) (*snapshot, error) {
+	previousKeys, err := gitConfigGetAllCommand(location, extraKeyOption)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, option := range userOptions(user) {
+		keys = append(keys, option.name)
+	}
+	keys = append(keys, previousKeys...)
+	for key := range user.GitConfig {
+		keys = append(keys, key)
+	}
+	keys = append(keys, extraKeyOption)
+
+	s := &snapshot{
+		location: location,
+		values:   make(map[string][]string),
+	}
+	for _, key := range keys {
+		if _, ok := s.values[key]; ok {
+			continue
+		}
+
+		values, err := gitConfigGetAllCommand(location, key)
+		if err != nil {
+			return nil, err
+		}
+		s.keys = append(s.keys, key)
+		s.values[key] = values
+	}
+
+	return s, nil
+}
+
+// restore restores all options that changed since the snapshot was taken and returns their keys
+func (s *snapshot) restore() ([]string, error) {
+	var restored []string
+	for _, key := range s.keys {
+		current, err := gitConfigGetAllCommand(s.location, key)
+		if err != nil {
+			return restored, err
+		}
+		if equalValues(current, s.values[key]) {
+			continue
+		}
+
+		err = gitConfigUnsetCommand(s.location, key)
+		if err != nil {
+			return restored, fmt.Errorf("failed to restore %s option via git: %w", key, err)
+		}
+
+		for _, value := range s.values[key] {
+			err = gitConfigCommand(s.location, "--add", key, value)
+			if err != nil {
+				return restored, fmt.Errorf("failed to restore %s option via git: %w", key, err)
+			}
+		}
+		restored = append(restored, key)
+	}
+
+	return restored, nil
+}
+
+// rollback restores the snapshot after setting the user failed with err and returns an error describing both the
+// failure and the options that were rolled back
+func (s *snapshot) rollback(err error) error {
+	restored, restoreErr := s.restore()
+	rolledBack := "no options changed"
+	if len(restored) > 0 {
+		rolledBack = "rolled back " + strings.Join(restored, ", ")
+	}
+
+	if restoreErr != nil {
+		return fmt.Errorf("%w (%s, rollback failed: %v)", err, rolledBack, restoreErr)
+	}
+	return fmt.Errorf("%w (%s)", err, rolledBack)
+}
+
+// equalValues returns if two lists of option values are equal
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrGitNotFound = errors.New("git not found on PATH")
+)
+
+// showScopeVersion is the git version introducing 'git config --show-scope'
+var showScopeVersion = Version{Major: 2, Minor: 26}
+
+// Version describes the version of git
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// GetVersion returns the version of the git executable on PATH via 'git --version'
+func GetVersion() (Version, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return Version{}, ErrGitNotFound
+	}
+
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to get git version: %w", err)
+	}
+	return parseVersion(string(out))
+}
+
+// AtLeast returns if the version is the given version or newer
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+// String formats the version as major.minor.patch
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// parseVersion parses the output of 'git --version', e.g. "git version 2.39.3 (Apple Git-145)" or
+// "git version 2.41.0.windows.1"
+func parseVersion(out string) (Version, error) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 {
+		return Version{}, fmt.Errorf("unexpected git version %q", strings.TrimSpace(out))
+	}
+
+	var numbers [3]int
+	for i, part := range strings.SplitN(fields[2], ".", 4) {
+		if i == len(numbers) {
+			break
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			if i == 0 {
+				return Version{}, fmt.Errorf("unexpected git version %q", strings.TrimSpace(out))
+			}
+			break
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
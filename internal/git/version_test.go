@@ -0,0 +1,52 @@
+package git
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		out     string
+		want    Version
+		wantErr bool
+	}{
+		{"git version 2.39.3\n", Version{2, 39, 3}, false},
+		{"git version 2.39.3 (Apple Git-145)\n", Version{2, 39, 3}, false},
+		{"git version 2.41.0.windows.1\n", Version{2, 41, 0}, false},
+		{"git version 2.45.rc0\n", Version{2, 45, 0}, false},
+		{"git version 2\n", Version{2, 0, 0}, false},
+		{"git version unknown\n", Version{}, true},
+		{"git\n", Version{}, true},
+		{"", Version{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.out, func(t *testing.T) {
+			got, err := parseVersion(test.out)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseVersion(%q) error = %v, want error %v", test.out, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("parseVersion(%q) = %s, want %s", test.out, got, test.want)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version      Version
+		major, minor int
+		want         bool
+	}{
+		{Version{2, 34, 0}, 2, 34, true},
+		{Version{2, 34, 1}, 2, 13, true},
+		{Version{3, 0, 0}, 2, 34, true},
+		{Version{2, 12, 5}, 2, 13, false},
+		{Version{1, 99, 0}, 2, 0, false},
+	}
+
+	for _, test := range tests {
+		if got := test.version.AtLeast(test.major, test.minor); got != test.want {
+			t.Errorf("%s.AtLeast(%d, %d) = %v, want %v", test.version, test.major, test.minor, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package gpg
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrGPGNotFound = errors.New("gpg not found on PATH")
+	ErrKeyNotFound = errors.New("no secret key with this ID or user ID in the GPG keyring")
+)
+
+// Key describes a secret key of the GPG keyring. UIDs only contains user IDs that are not revoked
+type Key struct {
+	ID          string
+	Fingerprint string
+	UIDs        []string
+	Subkeys     []Subkey
+	Expires     time.Time
+	Revoked     bool
+	Expired     bool
+	Disabled    bool
+}
+
+// Subkey describes a secret subkey of a key
+type Subkey struct {
+	ID          string
+	Fingerprint string
+}
+
+// ListSecretKeys returns the secret keys of the GPG keyring via 'gpg --list-secret-keys --with-colons'
+func ListSecretKeys() ([]Key, error) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		return nil, ErrGPGNotFound
+	}
+
+	out, err := exec.Command("gpg", "--list-secret-keys", "--with-colons", "--fixed-list-mode").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secret keys via gpg: %w", err)
+	}
+	return parseKeys(string(out)), nil
+}
+
+// FindKey returns the key with the given ID, which may be a short or long key ID or a fingerprint with optional "0x"
+// prefix of the key or one of its subkeys. A trailing "!" forcing the use of a subkey is ignored. Like gpg, a user ID
+// like "John Doe" or "<john@johndoe.dev>" selects the first key with a matching user ID, preferring usable keys
+func FindKey(keys []Key, id string) (*Key, error) {
+	keyID := strings.ToUpper(strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(id, "0x"), "0X"), "!"))
+	if !isKeyID(keyID) {
+		return findKeyByUID(keys, id)
+	}
+
+	for i, key := range keys {
+		if matchesKeyID(key.ID, key.Fingerprint, keyID) {
+			return &keys[i], nil
+		}
+		for _, subkey := range key.Subkeys {
+			if matchesKeyID(subkey.ID, subkey.Fingerprint, keyID) {
+				return &keys[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+}
+
+// findKeyByUID returns the first usable key with a user ID matching the search string or the first matching one if
+// none is usable. "<email>" matches the exact email, other strings match case-insensitive substrings
+func findKeyByUID(keys []Key, search string) (*Key, error) {
+	var found *Key
+	for i := range keys {
+		if !keys[i].hasUID(search) {
+			continue
+		}
+		if keys[i].Usable() {
+			return &keys[i], nil
+		}
+		if found == nil {
+			found = &keys[i]
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, search)
+	}
+	return found, nil
+}
+
+// hasUID returns if a user ID of the key matches the search string like gpg matches user IDs
+func (k *Key) hasUID(search string) bool {
+	search = strings.TrimSpace(search)
+	if strings.HasPrefix(search, "<") && strings.HasSuffix(search, ">") {
+		return k.HasEmail(search[1 : len(search)-1])
+	}
+
+	for _, uid := range k.UIDs {
+		if search != "" && strings.Contains(strings.ToLower(uid), strings.ToLower(search)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeyID returns if the string is a hexadecimal key ID or fingerprint, i.e. has at least 8 hex digits
+func isKeyID(s string) bool {
+	if len(s) < 8 {
+		return false
+	}
+	_, err := hex.DecodeString(strings.Repeat("0", len(s)%2) + s)
+	return err == nil
+}
+
+// matchesKeyID returns if the key ID is a suffix of the ID or fingerprint of a key
+func matchesKeyID(id, fingerprint, keyID string) bool {
+	return strings.HasSuffix(fingerprint, keyID) || strings.HasSuffix(id, keyID)
+}
+
+// HasEmail returns if a user ID of the key has the email, compared case-insensitively
+func (k *Key) HasEmail(email string) bool {
+	for _, uid := range k.UIDs {
+		if strings.EqualFold(uidEmail(uid), email) {
+			return true
+		}
+	}
+	return false
+}
+
+// Problems returns the reasons why the key should not be used to sign as any of the emails, i.e. if it is revoked,
+// expired, disabled or has no user ID with one of the emails
+func (k *Key) Problems(emails []string) []string {
+	var problems []string
+	if k.Revoked {
+		problems = append(problems, fmt.Sprintf("key %s is revoked", k.ID))
+	}
+	if k.Expired {
+		problems = append(problems, fmt.Sprintf("key %s expired on %s", k.ID, k.Expires.Format("2006-01-02")))
+	}
+	if k.Disabled {
+		problems = append(problems, fmt.Sprintf("key %s is disabled", k.ID))
+	}
+
+	for _, email := range emails {
+		if k.HasEmail(email) {
+			return problems
+		}
+	}
+	return append(problems, fmt.Sprintf("key %s has no user ID with %s", k.ID, strings.Join(emails, " or ")))
+}
+
+// Usable returns if the key is neither revoked, expired nor disabled
+func (k *Key) Usable() bool {
+	return !k.Revoked && !k.Expired && !k.Disabled
+}
+
+// Format formats the key as a string
+func (k *Key) Format() string {
+	s := k.ID
+	if len(k.UIDs) > 0 {
+		s += " " + k.UIDs[0]
+	}
+	if !k.Expires.IsZero() {
+		s += fmt.Sprintf(" (expires %s)", k.Expires.Format("2006-01-02"))
+	}
+	return s
+}
+
+// parseKeys parses the colon listing of secret keys. See doc/DETAILS in the GnuPG sources for the format
+func parseKeys(out string) []Key {
+	var keys []Key
+	var key *Key
+	var subkey *Subkey
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), ":")
+		if len(fields) < 10 {
+			continue
+		}
+
+		switch fields[0] {
+		case "sec":
+			keys = append(keys, Key{
+				ID:       fields[4],
+				Revoked:  fields[1] == "r",
+				Expired:  fields[1] == "e",
+				Expires:  parseTime(fields[6]),
+				Disabled: len(fields) > 11 && strings.Contains(fields[11], "D"),
+			})
+			key = &keys[len(keys)-1]
+			subkey = nil
+		case "ssb":
+			// Fingerprints following subkeys belong to the subkey
+			if key != nil {
+				key.Subkeys = append(key.Subkeys, Subkey{ID: fields[4]})
+				subkey = &key.Subkeys[len(key.Subkeys)-1]
+			}
+		case "fpr":
+			if subkey != nil && subkey.Fingerprint == "" {
+				subkey.Fingerprint = fields[9]
+			} else if subkey == nil && key != nil && key.Fingerprint == "" {
+				key.Fingerprint = fields[9]
+			}
+		case "uid":
+			if key != nil && subkey == nil && fields[1] != "r" {
+				key.UIDs = append(key.UIDs, unescape(fields[9]))
+			}
+		}
+	}
+
+	// Keys may expire before gpg updated the validity in its trust database
+	for i := range keys {
+		if !keys[i].Expires.IsZero() && keys[i].Expires.Before(time.Now()) {
+			keys[i].Expired = true
+		}
+	}
+	return keys
+}
+
+// parseTime parses a timestamp of the colon listing, which are seconds since epoch or ISO 8601 basic format
+func parseTime(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0)
+	}
+	t, err := time.Parse("20060102T150405", s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// unescape replaces the C-style "\xNN" escapes gpg uses for special characters in user IDs
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if n, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// uidEmail returns the email of a user ID like "Name (Comment) <email>", or the user ID itself if it is a plain email
+func uidEmail(uid string) string {
+	addr, err := mail.ParseAddress(uid)
+	if err != nil {
+		return strings.TrimSpace(uid)
+	}
+	return addr.Address
+}
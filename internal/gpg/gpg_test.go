@@ -0,0 +1,105 @@
+package gpg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// listing is the colon listing of a usable key with a signing subkey and of an expired key with a revoked user ID
+const listing = `sec:u:255:22:C34546F70846B43D:1714566600:::u:::cSC:::+::ed25519:::0:
+fpr:::::::::5841A9312019773B05089E83C34546F70846B43D:
+grp:::::::::99ABEDA7B4B40976CC00288068142731937BA913:
+uid:u::::1714566600::C0DEA9818185027C1444C9A9C29EF327ADF7A41C::Alice Work (B\x3aro) <alice@work.dev>::::::::::0:
+uid:u::::1714566600::254DA7F92C07C50B0F97A771896ABA7449EF2D4E::Alice <alice@corp.dev>::::::::::0:
+ssb:u:255:22:BB458987F27F5414:1714566600::::::s:::+::ed25519::
+fpr:::::::::74D7127FA8CB6DAA53DF1132BB458987F27F5414:
+grp:::::::::3FDE8A1AD9EAC1855F2A0150B35F39D646E8B8A7:
+sec:e:255:22:0123456789ABCDEF:1514764800:1546300800::u:::scSC:::+::ed25519:::0:
+fpr:::::::::00112233445566778899AABBCCDDEEFF0123456789ABCDEF:
+uid:r::::1514764800::1111111111111111111111111111111111111111::Bob <bob@old.dev>::::::::::0:
+uid:e::::1514764800::2222222222222222222222222222222222222222::Bob <bob@corp.dev>::::::::::0:
+`
+
+func TestParseKeys(t *testing.T) {
+	want := []Key{
+		{
+			ID:          "C34546F70846B43D",
+			Fingerprint: "5841A9312019773B05089E83C34546F70846B43D",
+			UIDs:        []string{"Alice Work (B:ro) <alice@work.dev>", "Alice <alice@corp.dev>"},
+			Subkeys:     []Subkey{{ID: "BB458987F27F5414", Fingerprint: "74D7127FA8CB6DAA53DF1132BB458987F27F5414"}},
+		},
+		{
+			ID:          "0123456789ABCDEF",
+			Fingerprint: "00112233445566778899AABBCCDDEEFF0123456789ABCDEF",
+			UIDs:        []string{"Bob <bob@corp.dev>"},
+			Expires:     time.Unix(1546300800, 0),
+			Expired:     true,
+		},
+	}
+
+	got := parseKeys(listing)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeys() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindKey(t *testing.T) {
+	keys := parseKeys(listing)
+	tests := []struct {
+		name string
+		id   string
+		want string
+		err  error
+	}{
+		{"long ID", "C34546F70846B43D", "C34546F70846B43D", nil},
+		{"short ID in lower case", "0846b43d", "C34546F70846B43D", nil},
+		{"fingerprint with prefix", "0x5841A9312019773B05089E83C34546F70846B43D", "C34546F70846B43D", nil},
+		{"subkey ID forced with !", "BB458987F27F5414!", "C34546F70846B43D", nil},
+		{"subkey fingerprint", "74D7127FA8CB6DAA53DF1132BB458987F27F5414", "C34546F70846B43D", nil},
+		{"unknown ID", "DEADBEEF", "", ErrKeyNotFound},
+		{"email in brackets", "<alice@work.dev>", "C34546F70846B43D", nil},
+		{"email in brackets of revoked user ID", "<bob@old.dev>", "", ErrKeyNotFound},
+		{"name", "bob", "0123456789ABCDEF", nil},
+		{"email substring", "corp.dev", "C34546F70846B43D", nil},
+		{"unknown user ID", "Carol", "", ErrKeyNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := FindKey(keys, test.id)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("FindKey(%q) error = %v, want %v", test.id, err, test.err)
+			}
+			if err == nil && key.ID != test.want {
+				t.Errorf("FindKey(%q) = %s, want %s", test.id, key.ID, test.want)
+			}
+		})
+	}
+}
+
+func TestKeyProblems(t *testing.T) {
+	keys := parseKeys(listing)
+	tests := []struct {
+		name     string
+		key      Key
+		emails   []string
+		problems int
+	}{
+		{"usable", keys[0], []string{"alice@corp.dev"}, 0},
+		{"further email", keys[0], []string{"alice@private.dev", "ALICE@work.dev"}, 0},
+		{"no user ID with email", keys[0], []string{"alice@private.dev"}, 1},
+		{"expired", keys[1], []string{"bob@corp.dev"}, 1},
+		{"expired and revoked user ID", keys[1], []string{"bob@old.dev"}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := test.key.Problems(test.emails)
+			if len(problems) != test.problems {
+				t.Errorf("Problems(%v) = %v, want %d problem(s)", test.emails, problems, test.problems)
+			}
+		})
+	}
+}
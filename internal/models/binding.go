@@ -0,0 +1,46 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Binding describes the structure of the binding JSON data. A binding maps a directory to a user alias via an
+// includeIf section in the global git config
+type Binding struct {
+	Dir   string `json:"dir" yaml:"dir" toml:"dir"`
+	Alias string `json:"alias" yaml:"alias" toml:"alias"`
+}
+
+// NewBinding returns a new binding. The directory always ends with a slash so that it matches all repositories
+// below it
+func NewBinding(dir, alias string) *Binding {
+	if !strings.HasSuffix(dir, "/") {
+		dir = dir + "/"
+	}
+	return &Binding{
+		Dir:   dir,
+		Alias: alias,
+	}
+}
+
+// Condition returns the includeIf condition of the binding
+func (b *Binding) Condition() string {
+	return "gitdir:" + b.Dir
+}
+
+// Contains returns if the directory is the bound directory or below it. A leading "~/" of the bound directory is
+// expanded to home
+func (b *Binding) Contains(dir, home string) bool {
+	bound := b.Dir
+	if strings.HasPrefix(bound, "~/") {
+		bound = strings.TrimSuffix(filepath.ToSlash(home), "/") + bound[1:]
+	}
+	return strings.HasPrefix(strings.TrimSuffix(filepath.ToSlash(dir), "/")+"/", bound)
+}
+
+// Format formats binding data as a string
+func (b *Binding) Format(padding int) string {
+	return fmt.Sprintf("%-*s -> [%s]", padding, b.Dir, b.Alias)
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrPlaceholderEmail defines the error when an email address is an obvious placeholder
+	ErrPlaceholderEmail = errors.New("placeholder email")
+
+	// ErrEmailDomainNotAllowed defines the error when the domain of an email address is not in the allowed domains
+	ErrEmailDomainNotAllowed = errors.New("email domain not allowed")
+
+	// ErrInvalidNoreplyEmail defines the error when a GitHub noreply email address is malformed
+	ErrInvalidNoreplyEmail = errors.New("invalid GitHub noreply email, expected <id>+<username>@" + githubNoreplyDomain)
+)
+
+// githubNoreplyDomain is the domain of the noreply email addresses GitHub assigns to its users
+const githubNoreplyDomain = "users.noreply.github.com"
+
+// githubNoreplyRegexp matches the local part of GitHub noreply addresses: an optional numeric user ID followed by a
+// '+' and the username, which consists of up to 39 alphanumeric characters or single inner hyphens
+var githubNoreplyRegexp = regexp.MustCompile(`^([0-9]+\+)?[A-Za-z0-9]([A-Za-z0-9-]{0,37}[A-Za-z0-9])?$`)
+
+// placeholderDomains are reserved domains (RFC 2606, RFC 6761) and domains of addresses git makes up if no email is
+// configured. Only the exact domains are placeholders, as real domains like corp.example are common in test setups
+var placeholderDomains = []string{
+	"example",
+	"example.com",
+	"example.net",
+	"example.org",
+	"test",
+	"invalid",
+	"localhost",
+	"localdomain",
+}
+
+// placeholderLocalParts are local parts of addresses copied from documentation without being adapted
+var placeholderLocalParts = []string{
+	"you",
+	"your.email",
+	"youremail",
+	"your_email",
+	"your-email",
+	"your.name",
+	"yourname",
+	"email",
+	"username",
+}
+
+// EmailPolicy describes which email addresses are accepted for user profiles in addition to syntactically valid ones.
+// An empty list of allowed domains allows all domains, subdomains of allowed domains are allowed as well
+type EmailPolicy struct {
+	AllowedDomains    []string `json:"allowed_domains,omitempty" yaml:"allowed_domains,omitempty" toml:"allowed_domains,omitempty"`
+	AllowPlaceholders bool     `json:"allow_placeholders,omitempty" yaml:"allow_placeholders,omitempty" toml:"allow_placeholders,omitempty"`
+}
+
+// Validate validates the email address against the policy. It checks the syntax, the format of GitHub noreply
+// addresses, placeholders and the allowed domains, all without network lookups
+func (p *EmailPolicy) Validate(email string) error {
+	err := ValidateEmail(email)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, email)
+	}
+
+	i := strings.LastIndex(email, "@")
+	local, domain := email[:i], strings.ToLower(email[i+1:])
+
+	if domain == githubNoreplyDomain && !githubNoreplyRegexp.MatchString(local) {
+		return fmt.Errorf("%w: %s", ErrInvalidNoreplyEmail, email)
+	}
+
+	// An explicitly allowed domain is not a placeholder
+	allowed := len(p.AllowedDomains) > 0 && matchesDomain(domain, p.AllowedDomains)
+	if !p.AllowPlaceholders && !allowed && IsPlaceholderEmail(email) {
+		return fmt.Errorf("%w: %s", ErrPlaceholderEmail, email)
+	}
+
+	if len(p.AllowedDomains) > 0 && !allowed {
+		return fmt.Errorf("%w: %s, expected %s", ErrEmailDomainNotAllowed, email, strings.Join(p.AllowedDomains, ", "))
+	}
+	return nil
+}
+
+// IsPlaceholderEmail returns if the email address is an obvious placeholder, i.e. it uses a reserved domain or a
+// local part like "you" or "your.email"
+func IsPlaceholderEmail(email string) bool {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return false
+	}
+
+	local, domain := strings.ToLower(email[:i]), strings.ToLower(email[i+1:])
+	for _, placeholder := range placeholderDomains {
+		if domain == placeholder {
+			return true
+		}
+	}
+	for _, placeholder := range placeholderLocalParts {
+		if local == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeDomain returns the domain in lower case without a leading '@' or '.', so that "@Corp.Example" can be
+// used to configure the allowed domain corp.example
+func NormalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimLeft(strings.TrimSpace(domain), "@."))
+}
+
+// matchesDomain returns if the domain equals or is a subdomain of one of the domains
+func matchesDomain(domain string, domains []string) bool {
+	for _, d := range domains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmailPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy EmailPolicy
+		email  string
+		err    error
+	}{
+		{"valid", EmailPolicy{}, "john@corp.dev", nil},
+		{"malformed", EmailPolicy{}, "john.corp.dev", ErrInvalidEmail},
+		{"empty", EmailPolicy{}, "", ErrInvalidEmail},
+		{"reserved domain", EmailPolicy{}, "john@example.com", ErrPlaceholderEmail},
+		{"reserved domain in upper case", EmailPolicy{}, "john@Example.COM", ErrPlaceholderEmail},
+		{"subdomain of reserved TLD", EmailPolicy{}, "john@corp.example", nil},
+		{"subdomain of reserved domain", EmailPolicy{}, "john@mail.example.com", nil},
+		{"placeholder local part", EmailPolicy{}, "your.email@corp.dev", ErrPlaceholderEmail},
+		{"placeholders allowed", EmailPolicy{AllowPlaceholders: true}, "you@example.com", nil},
+		{"allowed domain", EmailPolicy{AllowedDomains: []string{"corp.dev"}}, "john@corp.dev", nil},
+		{"allowed subdomain", EmailPolicy{AllowedDomains: []string{"corp.dev"}}, "john@eu.corp.dev", nil},
+		{"domain not allowed", EmailPolicy{AllowedDomains: []string{"corp.dev"}}, "john@johndoe.dev", ErrEmailDomainNotAllowed},
+		{"suffix is no subdomain", EmailPolicy{AllowedDomains: []string{"corp.dev"}}, "john@notcorp.dev", ErrEmailDomainNotAllowed},
+		{"explicitly allowed reserved domain", EmailPolicy{AllowedDomains: []string{"example.com"}}, "john@example.com", nil},
+		{"explicitly allowed placeholder local part", EmailPolicy{AllowedDomains: []string{"corp.dev"}}, "you@corp.dev", nil},
+		{"noreply", EmailPolicy{}, "12345+john-doe@users.noreply.github.com", nil},
+		{"noreply without ID", EmailPolicy{}, "john-doe@users.noreply.github.com", nil},
+		{"malformed noreply", EmailPolicy{}, "john_doe@users.noreply.github.com", ErrInvalidNoreplyEmail},
+		{"noreply with trailing hyphen", EmailPolicy{}, "1+john-@users.noreply.github.com", ErrInvalidNoreplyEmail},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.policy.Validate(test.email)
+			if !errors.Is(err, test.err) {
+				t.Errorf("Validate(%q) error = %v, want %v", test.email, err, test.err)
+			}
+		})
+	}
+}
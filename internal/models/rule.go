@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Rule describes the structure of the rule JSON data. A rule maps a remote URL pattern to a user alias
+type Rule struct {
+	Pattern string `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Alias   string `json:"alias" yaml:"alias" toml:"alias"`
+}
+
+// NewRule returns a new rule
+func NewRule(pattern, alias string) *Rule {
+	return &Rule{
+		Pattern: strings.ToLower(strings.TrimSuffix(pattern, "/")),
+		Alias:   alias,
+	}
+}
+
+// Validate returns an error if the rule pattern is malformed
+func (r *Rule) Validate() error {
+	_, err := path.Match(r.Pattern, "")
+	return err
+}
+
+// Matches returns if the rule matches the remote URL. The pattern is matched against the host and every leading
+// path of the remote, so "github.com" matches the host, "github.com/org" matches an organization and globs like
+// "*.corp.example" or "github.com/*-corp" are possible
+func (r *Rule) Matches(remoteURL string) bool {
+	return r.MatchDepth(remoteURL) >= 0
+}
+
+// MatchDepth returns the number of path segments of the remote URL the rule matches in addition to the host or -1 if
+// the rule does not match. A deeper match is more specific, e.g. "github.com/org" matches with depth 1 and
+// "github.com" with depth 0
+func (r *Rule) MatchDepth(remoteURL string) int {
+	host, repoPath := ParseRemoteURL(remoteURL)
+	if host == "" {
+		return -1
+	}
+
+	target := host
+	depth := 0
+	for _, segment := range append([]string{""}, strings.Split(repoPath, "/")...) {
+		if segment != "" {
+			target = target + "/" + segment
+			depth++
+		}
+		if ok, _ := path.Match(r.Pattern, target); ok {
+			return depth
+		}
+	}
+	return -1
+}
+
+// Format formats rule data as a string
+func (r *Rule) Format(padding int) string {
+	return fmt.Sprintf("%-*s -> [%s]", padding, r.Pattern, r.Alias)
+}
+
+// ParseRemoteURL returns the lower cased host and repository path (without the '.git' suffix) of a git remote URL.
+// Both URL style ("https://host/org/repo.git", "ssh://git@host:22/org/repo") and scp style ("git@host:org/repo")
+// remotes are supported. The host is empty for local remotes
+func ParseRemoteURL(remoteURL string) (string, string) {
+	var host, repoPath string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", ""
+		}
+		host, repoPath = u.Hostname(), u.Path
+	} else {
+		colon := strings.Index(remoteURL, ":")
+		slash := strings.Index(remoteURL, "/")
+		// A colon after a slash or a drive letter (C:\repo) denotes a local path
+		if colon < 2 || (slash >= 0 && slash < colon) {
+			return "", ""
+		}
+		host, repoPath = remoteURL[:colon], remoteURL[colon+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	return strings.ToLower(host), strings.ToLower(repoPath)
+}
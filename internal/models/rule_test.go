@@ -0,0 +1,61 @@
+package models
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		remoteURL string
+		host      string
+		repoPath  string
+	}{
+		{"https://github.com/Corp/Repo.git", "github.com", "corp/repo"},
+		{"https://user@gitlab.corp.dev/group/sub/repo", "gitlab.corp.dev", "group/sub/repo"},
+		{"ssh://git@github.com:22/corp/repo.git", "github.com", "corp/repo"},
+		{"git@github.com:corp/repo.git", "github.com", "corp/repo"},
+		{"github.com:corp/repo/", "github.com", "corp/repo"},
+		{"/srv/git/repo.git", "", ""},
+		{"../repo", "", ""},
+		{"C:\\repo", "", ""},
+		{"./dir:with/colon", "", ""},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.remoteURL, func(t *testing.T) {
+			host, repoPath := ParseRemoteURL(test.remoteURL)
+			if host != test.host || repoPath != test.repoPath {
+				t.Errorf("ParseRemoteURL(%q) = %q, %q, want %q, %q", test.remoteURL, host, repoPath, test.host, test.repoPath)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		remoteURL string
+		matches   bool
+	}{
+		{"github.com", "git@github.com:corp/repo.git", true},
+		{"github.com/corp", "https://github.com/corp/repo.git", true},
+		{"github.com/corp", "https://github.com/Corp/repo.git", true},
+		{"github.com/corp", "https://github.com/corporation/repo.git", false},
+		{"github.com/corp/", "https://github.com/corp/repo.git", true},
+		{"github.com/corp/repo", "https://github.com/corp/repo.git", true},
+		{"*.corp.dev", "git@gitlab.corp.dev:team/repo.git", true},
+		{"*.corp.dev", "git@corp.dev:team/repo.git", false},
+		{"github.com/*-corp", "https://github.com/acme-corp/repo", true},
+		{"github.com/*-corp", "https://github.com/acme/repo", false},
+		{"gitlab.com", "https://github.com/corp/repo", false},
+		{"github.com", "/srv/git/repo.git", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.remoteURL, func(t *testing.T) {
+			rule := NewRule(test.pattern, "work")
+			if got := rule.Matches(test.remoteURL); got != test.matches {
+				t.Errorf("Matches(%q) = %v, want %v", test.remoteURL, got, test.matches)
+			}
+		})
+	}
+}
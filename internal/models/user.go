@@ -3,7 +3,12 @@ package models
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
+
+	"github.com/matsuyoshi30/gitsu/internal/utils"
 
 	"github.com/asaskevich/govalidator"
 )
@@ -11,73 +16,365 @@ import (
 var (
 	// ErrInvalidEmail defines the error when an invalid email address is encountered
 	ErrInvalidEmail = errors.New("invalid email")
+
+	// ErrInvalidGitConfigKey defines the error when an extra git config key is malformed or managed by gitsu
+	ErrInvalidGitConfigKey = errors.New("invalid git config key")
+
+	// ErrUnknownEmail defines the error when an email address does not belong to a user
+	ErrUnknownEmail = errors.New("email does not belong to the user")
+
+	// ErrInvalidTag defines the error when a malformed tag is encountered
+	ErrInvalidTag = errors.New("invalid tag, tags must not be empty or contain whitespace or commas")
+
+	// ErrInvalidSigningFormat defines the error when an unknown signing format is encountered
+	ErrInvalidSigningFormat = errors.New("invalid signing format, expected openpgp, ssh or x509")
 )
 
-// User describes the structure of the user JSON data
+// ManagedGitConfigKeys are the git config keys gitsu derives from the user profile fields. They can not be set as
+// extra git config keys
+var ManagedGitConfigKeys = []string{
+	"user.name",
+	"user.email",
+	"user.signingkey",
+	"gpg.format",
+	"gpg.ssh.allowedSignersFile",
+	"commit.gpgsign",
+	"tag.gpgsign",
+	"core.sshCommand",
+}
+
+// gitConfigKeyRegexp matches git config keys. Section and name consist of alphanumeric characters and dashes, the
+// optional subsection may contain anything but newlines
+var gitConfigKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+(\..+)?\.[A-Za-z][A-Za-z0-9-]*$`)
+
+// SigningFormats are the signing formats supported by git's gpg.format option
+var SigningFormats = []string{"openpgp", "ssh", "x509"}
+
+// User describes the structure of the user JSON data. SigningKey is the value of git's user.signingkey option, i.e.
+// a GPG key ID, the path of an SSH key or an X.509 certificate ID depending on SigningFormat. SSHKey is the identity
+// file used to authenticate against remotes. Email is the primary email address, Emails are further addresses the
+// user commits with, e.g. a noreply address of the hosting service. Tags group users, e.g. by client.
+// AllowedDomains replace the allowed domains of the global email policy for this user. ID identifies the profile
+// independent of its position and alias, Order is the sort key of the user list
 type User struct {
-	Name       string    `json:"name"`
-	Email      string    `json:"email"`
-	Alias      string    `json:"alias"`
-	GpgKeyID   string    `json:"gpg_key_id"`
-	AddedAt    time.Time `json:"added_at"`
-	ModifiedAt time.Time `json:"modified_at"`
+	ID                 string            `json:"id" yaml:"id" toml:"id"`
+	Order              int               `json:"order" yaml:"order" toml:"order"`
+	Name               string            `json:"name" yaml:"name" toml:"name"`
+	Email              string            `json:"email" yaml:"email" toml:"email"`
+	Emails             []string          `json:"emails,omitempty" yaml:"emails,omitempty" toml:"emails,omitempty"`
+	Alias              string            `json:"alias" yaml:"alias" toml:"alias"`
+	Tags               []string          `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
+	SigningKey         string            `json:"signing_key" yaml:"signing_key" toml:"signing_key"`
+	SigningFormat      string            `json:"signing_format,omitempty" yaml:"signing_format,omitempty" toml:"signing_format,omitempty"`
+	SignCommits        bool              `json:"sign_commits,omitempty" yaml:"sign_commits,omitempty" toml:"sign_commits,omitempty"`
+	SignTags           bool              `json:"sign_tags,omitempty" yaml:"sign_tags,omitempty" toml:"sign_tags,omitempty"`
+	AllowedSignersFile string            `json:"allowed_signers_file,omitempty" yaml:"allowed_signers_file,omitempty" toml:"allowed_signers_file,omitempty"`
+	SSHKey             string            `json:"ssh_key,omitempty" yaml:"ssh_key,omitempty" toml:"ssh_key,omitempty"`
+	GitConfig          map[string]string `json:"git_config,omitempty" yaml:"git_config,omitempty" toml:"git_config,omitempty"`
+	AllowedDomains     []string          `json:"allowed_domains,omitempty" yaml:"allowed_domains,omitempty" toml:"allowed_domains,omitempty"`
+	AddedAt            time.Time         `json:"added_at" yaml:"added_at" toml:"added_at"`
+	ModifiedAt         time.Time         `json:"modified_at" yaml:"modified_at" toml:"modified_at"`
+}
+
+// UserPatch describes changes to a user profile. Nil fields are left unchanged
+type UserPatch struct {
+	Name               *string
+	Email              *string
+	Alias              *string
+	SigningKey         *string
+	SigningFormat      *string
+	SignCommits        *bool
+	SignTags           *bool
+	AllowedSignersFile *string
+	SSHKey             *string
+	AddEmails          []string
+	RemoveEmails       []string
+	AddTags            []string
+	RemoveTags         []string
+	AllowedDomains     *[]string
+	SetGitConfig       map[string]string
+	UnsetGitConfig     []string
 }
 
 // NewUser returns a new user
-func NewUser(name, email, alias, gpgKeyID string) *User {
+func NewUser(name, email, alias, signingKey string) *User {
 	return &User{
-		Name:     name,
-		Email:    email,
-		Alias:    alias,
-		GpgKeyID: gpgKeyID,
+		Name:       name,
+		Email:      email,
+		Alias:      alias,
+		SigningKey: signingKey,
 	}
 }
 
-// Modify updates fields if there are changes. It also updated the 'ModifiedAt' field accordingly
-func (u *User) Modify(name, email, alias, gpgKeyID string) {
-	var modified = 0
-	if name != "" {
-		u.Name = name
-		modified++
+// Apply updates fields if there are changes. It also updated the 'ModifiedAt' field accordingly
+func (u *User) Apply(p *UserPatch) {
+	var modified = u.applyEmails(p) + u.applyTags(p)
+	for _, field := range []struct {
+		value *string
+		patch *string
+	}{
+		{&u.Name, p.Name},
+		{&u.Alias, p.Alias},
+		{&u.SigningKey, p.SigningKey},
+		{&u.SigningFormat, p.SigningFormat},
+		{&u.AllowedSignersFile, p.AllowedSignersFile},
+		{&u.SSHKey, p.SSHKey},
+	} {
+		if field.patch != nil && *field.patch != *field.value {
+			*field.value = *field.patch
+			modified++
+		}
 	}
 
-	if email != "" {
-		u.Email = email
+	if p.SignCommits != nil && *p.SignCommits != u.SignCommits {
+		u.SignCommits = *p.SignCommits
 		modified++
 	}
 
-	if alias != "" {
-		u.Alias = alias
+	if p.AllowedDomains != nil && strings.Join(*p.AllowedDomains, ",") != strings.Join(u.AllowedDomains, ",") {
+		u.AllowedDomains = *p.AllowedDomains
 		modified++
 	}
 
-	if gpgKeyID != "" {
-		u.GpgKeyID = gpgKeyID
+	if p.SignTags != nil && *p.SignTags != u.SignTags {
+		u.SignTags = *p.SignTags
 		modified++
 	}
 
+	// The map may be shared with a copy of the user, so it is only changed on a copy of its own
+	if len(p.SetGitConfig) > 0 || len(p.UnsetGitConfig) > 0 {
+		gitConfig := make(map[string]string, len(u.GitConfig))
+		for key, value := range u.GitConfig {
+			gitConfig[key] = value
+		}
+		u.GitConfig = gitConfig
+	}
+
+	for key, value := range p.SetGitConfig {
+		if current, ok := u.GitConfig[key]; !ok || current != value {
+			u.GitConfig[key] = value
+			modified++
+		}
+	}
+
+	for _, key := range p.UnsetGitConfig {
+		if _, ok := u.GitConfig[key]; ok {
+			delete(u.GitConfig, key)
+			modified++
+		}
+	}
+
 	if modified > 0 {
 		u.ModifiedAt = time.Now()
 	}
 }
 
-// Format formats user profile data as a string
+// applyEmails updates the primary and the further emails and returns the number of changes. If a further email
+// becomes the primary one, the previous primary email takes its place
+func (u *User) applyEmails(p *UserPatch) int {
+	if p.Email == nil && len(p.AddEmails) == 0 && len(p.RemoveEmails) == 0 {
+		return 0
+	}
+
+	// The slice may be shared with a copy of the user, so it is only changed on a copy of its own
+	modified := 0
+	emails := append([]string{}, u.Emails...)
+	if p.Email != nil && *p.Email != u.Email {
+		for i, email := range emails {
+			if strings.EqualFold(email, *p.Email) {
+				emails[i] = u.Email
+			}
+		}
+		u.Email = *p.Email
+		modified++
+	}
+
+	for _, email := range p.AddEmails {
+		if !u.HasEmail(email) && !containsEmail(emails, email) {
+			emails = append(emails, email)
+			modified++
+		}
+	}
+
+	for _, email := range p.RemoveEmails {
+		for i := 0; i < len(emails); i++ {
+			if strings.EqualFold(emails[i], email) {
+				emails = append(emails[:i], emails[i+1:]...)
+				modified++
+				i--
+			}
+		}
+	}
+
+	u.Emails = emails
+	if len(u.Emails) == 0 {
+		u.Emails = nil
+	}
+	return modified
+}
+
+// applyTags adds and removes tags and returns the number of changes
+func (u *User) applyTags(p *UserPatch) int {
+	if len(p.AddTags) == 0 && len(p.RemoveTags) == 0 {
+		return 0
+	}
+
+	// The slice may be shared with a copy of the user, so it is only changed on a copy of its own
+	modified := 0
+	tags := append([]string{}, u.Tags...)
+	for _, tag := range p.AddTags {
+		if !u.HasTags([]string{tag}) {
+			tags = append(tags, tag)
+			modified++
+		}
+	}
+
+	for _, tag := range p.RemoveTags {
+		for i, t := range tags {
+			if t == tag {
+				tags = append(tags[:i], tags[i+1:]...)
+				modified++
+				break
+			}
+		}
+	}
+
+	u.Tags = tags
+	if len(u.Tags) == 0 {
+		u.Tags = nil
+	}
+	return modified
+}
+
+// HasTags returns if the user has all of the given tags
+func (u *User) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range u.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AllEmails returns the primary email followed by the further emails of the user
+func (u *User) AllEmails() []string {
+	return append([]string{u.Email}, u.Emails...)
+}
+
+// HasEmail returns if the email is the primary or a further email of the user. Emails are compared case-insensitively
+func (u *User) HasEmail(email string) bool {
+	return containsEmail(u.AllEmails(), email)
+}
+
+// WithEmail returns a copy of the user with the given email as primary email, e.g. to apply a further email to git
+func (u *User) WithEmail(email string) (*User, error) {
+	if !u.HasEmail(email) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEmail, email)
+	}
+
+	user := *u
+	user.Email = email
+	return &user, nil
+}
+
+// containsEmail returns if the list contains the email, compared case-insensitively
+func containsEmail(emails []string, email string) bool {
+	for _, e := range emails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// SSHCommand returns the value of git's core.sshCommand option that makes ssh authenticate with the user's SSH key
+// only, or an empty string if the user has no SSH key
+func (u *User) SSHCommand() string {
+	if u.SSHKey == "" {
+		return ""
+	}
+	return fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", utils.ShellQuote(u.SSHKey))
+}
+
+// Format formats user profile data as a string, the label padded to padding characters
 func (u *User) Format(padding int) string {
-	if u.Alias != "" {
-		return fmt.Sprintf("%-*s %s <%s>", padding, fmt.Sprintf("[%s]", u.Alias), u.Name, u.Email)
+	if label := u.Label(); label != "" || padding > 0 {
+		return fmt.Sprintf("%-*s %s <%s>", padding, label, u.Name, u.Email)
 	}
-	return fmt.Sprintf("%-*s %s <%s>", padding, "", u.Name, u.Email)
+	return fmt.Sprintf("%s <%s>", u.Name, u.Email)
 }
 
-// ValidateEmail validates the provided email address. If is was modified this function also accepts empty addresses
-// (= no change)
-func ValidateEmail(email string, modified bool) error {
-	if modified && email == "" {
-		return nil
+// Label returns the alias in brackets identifying the user, or the ID for users without alias
+func (u *User) Label() string {
+	if u.Alias != "" {
+		return fmt.Sprintf("[%s]", u.Alias)
 	}
+	return u.ID
+}
 
-	if !govalidator.IsExistingEmail(email) {
+// ValidateEmail validates the syntax of the provided email address without network lookups
+func ValidateEmail(email string) error {
+	if !govalidator.IsEmail(email) {
 		return ErrInvalidEmail
 	}
 	return nil
 }
+
+// ValidateEmails validates the primary and the further emails of the user against the policy
+func (u *User) ValidateEmails(policy EmailPolicy) error {
+	for _, email := range u.AllEmails() {
+		err := policy.Validate(email)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateGitConfig validates the keys of the user's extra git config. Keys need a section and a name
+// ("section.name" or "section.subsection.name") and must not be managed by gitsu
+func (u *User) ValidateGitConfig() error {
+	for key := range u.GitConfig {
+		if !gitConfigKeyRegexp.MatchString(key) {
+			return fmt.Errorf("%w: %s", ErrInvalidGitConfigKey, key)
+		}
+
+		for _, managed := range ManagedGitConfigKeys {
+			if strings.EqualFold(key, managed) {
+				return fmt.Errorf("%w: %s is set from the profile fields", ErrInvalidGitConfigKey, key)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTags validates the user's tags, which must not be empty or contain whitespace or commas
+func (u *User) ValidateTags() error {
+	for _, tag := range u.Tags {
+		if tag == "" || strings.IndexFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) >= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+		}
+	}
+	return nil
+}
+
+// ValidateSigningFormat validates the provided signing format. An empty format means git's default (openpgp)
+func ValidateSigningFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+
+	for _, f := range SigningFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return ErrInvalidSigningFormat
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // FileExists returns if file at 'path' exists
 func FileExists(path string) bool {
@@ -17,3 +21,16 @@ func DirExists(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
+
+// ExpandHome replaces a leading "~/" of 'path' with the home directory of the user
+func ExpandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ShellQuote quotes a string for the shell if it contains characters other than safe path characters. A leading
+// "~/" is kept outside of the quotes so that the shell still expands it
+func ShellQuote(s string) string {
+	if strings.HasPrefix(s, "~/") {
+		return "~/" + ShellQuote(s[2:])
+	}
+
+	if s == "" {
+		return "''"
+	}
+
+	safe := strings.IndexFunc(s, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("/._-~+:@", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
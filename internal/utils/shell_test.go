@@ -0,0 +1,27 @@
+package utils
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"/usr/local/bin/gitsu", "/usr/local/bin/gitsu"},
+		{"~/.ssh/id_ed25519", "~/.ssh/id_ed25519"},
+		{"C:/Users/john/gitsu.exe", "C:/Users/john/gitsu.exe"},
+		{"/Users/john/My Tools/gitsu", "'/Users/john/My Tools/gitsu'"},
+		{"~/My Keys/id_rsa", "~/'My Keys/id_rsa'"},
+		{"/tmp/john's/gitsu", `'/tmp/john'\''s/gitsu'`},
+		{"/tmp/$(reboot)", "'/tmp/$(reboot)'"},
+		{"", "''"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			if got := ShellQuote(test.s); got != test.want {
+				t.Errorf("ShellQuote(%q) = %s, want %s", test.s, got, test.want)
+			}
+		})
+	}
+}